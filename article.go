@@ -0,0 +1,338 @@
+package realworld
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Article struct {
+	// ID is assigned by [ArticleRepository] and never changes unlike Slug
+	ID          int64
+	Slug        string
+	Title       string
+	Description string
+	Body        string
+	// TagList is normalized by [ArticleService] and indexed by [ArticleRepository]
+	TagList   []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// AuthorID references the author stored in [UserRepository]
+	AuthorID string
+	// Author is resolved from AuthorID for the viewer and never persisted
+	Author Profile
+	// FavoritesCount is maintained by [ArticleRepository] and ignored on create and update
+	FavoritesCount int
+	// Favorited is computed for the viewer and never persisted
+	Favorited bool
+}
+
+// ArticleUpdate holds the fields of [Article] to change. nil fields are left untouched.
+type ArticleUpdate struct {
+	Title       *string
+	Description *string
+	Body        *string
+}
+
+// ArticleFilter narrows down articles by tag and by usernames of author and user who favorited.
+type ArticleFilter struct {
+	Tag       string
+	Author    string
+	Favorited string
+}
+
+// Page is limit and offset pagination. Zero Limit means [DefaultPageLimit].
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+const DefaultPageLimit = 20
+
+// ArticleQuery is filtered and paginated query for articles listed newest first.
+// Unlike [ArticleFilter], users are referenced by ID.
+type ArticleQuery struct {
+	Tag         string
+	Author      string
+	FavoritedBy string
+	Page
+}
+
+// FeedQuery is paginated query for articles by any of Authors listed newest first.
+// Authors are referenced by ID.
+type FeedQuery struct {
+	Authors []string
+	Page
+}
+
+type ArticleRepository interface {
+	CreateArticle(ctx context.Context, article Article) (Article, error)
+	// UpdateArticle replaces the article stored with slug, which may differ from article.Slug.
+	UpdateArticle(ctx context.Context, slug string, article Article) (Article, error)
+	DeleteArticle(ctx context.Context, slug string) error
+	FindArticleBySlug(ctx context.Context, slug string) (Article, error)
+	// ListArticles returns a page of articles matching query and total count of matching articles
+	ListArticles(ctx context.Context, query ArticleQuery) ([]Article, int, error)
+	// ListFeed returns a page of articles matching query and total count of matching articles
+	ListFeed(ctx context.Context, query FeedQuery) ([]Article, int, error)
+	// FavoriteArticle and UnfavoriteArticle are idempotent and return article with updated FavoritesCount
+	FavoriteArticle(ctx context.Context, slug, userID string) (Article, error)
+	UnfavoriteArticle(ctx context.Context, slug, userID string) (Article, error)
+	IsFavorited(ctx context.Context, slug, userID string) (bool, error)
+	// FavoritedAmong returns those of article ids userID favorited
+	FavoritedAmong(ctx context.Context, userID string, ids []int64) ([]int64, error)
+}
+
+type ArticleService struct {
+	repo    ArticleRepository
+	users   UserRepository
+	follows FollowRepository
+}
+
+func NewArticleService(repo ArticleRepository, users UserRepository, follows FollowRepository) ArticleService {
+	return ArticleService{repo: repo, users: users, follows: follows}
+}
+
+func (a ArticleService) CreateArticle(ctx context.Context, author string, article Article) (Article, error) {
+	now := time.Now()
+	article.AuthorID = author
+	article.TagList = normalizeTags(article.TagList)
+	article.CreatedAt = now
+	article.UpdatedAt = now
+	article.Slug = slugify(article.Title)
+	created, err := retryWithSlugSuffix(article, func(article Article) (Article, error) {
+		return a.repo.CreateArticle(ctx, article)
+	})
+	if err != nil {
+		return Article{}, err
+	}
+	return a.forViewer(ctx, author, created)
+}
+
+// FindArticleBySlug returns article as seen by viewer ID, which is empty for anonymous viewer.
+func (a ArticleService) FindArticleBySlug(ctx context.Context, viewer, slug string) (Article, error) {
+	article, err := a.repo.FindArticleBySlug(ctx, slug)
+	if err != nil {
+		return Article{}, err
+	}
+	return a.forViewer(ctx, viewer, article)
+}
+
+// ListArticles returns a page of articles as seen by viewer and total count of articles matching filter
+func (a ArticleService) ListArticles(ctx context.Context, viewer string, filter ArticleFilter, page Page) ([]Article, int, error) {
+	if page.Limit == 0 {
+		page.Limit = DefaultPageLimit
+	}
+	query := ArticleQuery{Tag: normalizeTag(filter.Tag), Page: page}
+	var err error
+	if query.Author, err = a.idOf(ctx, filter.Author); err != nil {
+		return a.emptyIfUserNotFound(err)
+	}
+	if query.FavoritedBy, err = a.idOf(ctx, filter.Favorited); err != nil {
+		return a.emptyIfUserNotFound(err)
+	}
+	articles, count, err := a.repo.ListArticles(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return a.forViewerAll(ctx, viewer, articles, count)
+}
+
+// Feed returns a page of articles by users viewer follows and total count of them
+func (a ArticleService) Feed(ctx context.Context, viewer string, page Page) ([]Article, int, error) {
+	if page.Limit == 0 {
+		page.Limit = DefaultPageLimit
+	}
+	followees, err := a.follows.Followees(ctx, viewer)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(followees) == 0 {
+		return []Article{}, 0, nil
+	}
+	articles, count, err := a.repo.ListFeed(ctx, FeedQuery{Authors: followees, Page: page})
+	if err != nil {
+		return nil, 0, err
+	}
+	return a.forViewerAll(ctx, viewer, articles, count)
+}
+
+func (a ArticleService) idOf(ctx context.Context, username string) (string, error) {
+	if username == "" {
+		return "", nil
+	}
+	user, err := a.users.FindUserByUsername(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
+
+// emptyIfUserNotFound treats filtering by unknown user as filter matching nothing
+func (a ArticleService) emptyIfUserNotFound(err error) ([]Article, int, error) {
+	if errors.Is(err, ErrUserNotFound) {
+		return []Article{}, 0, nil
+	}
+	return nil, 0, err
+}
+
+func (a ArticleService) FavoriteArticle(ctx context.Context, user, slug string) (Article, error) {
+	article, err := a.repo.FavoriteArticle(ctx, slug, user)
+	if err != nil {
+		return Article{}, err
+	}
+	return a.forViewer(ctx, user, article)
+}
+
+func (a ArticleService) UnfavoriteArticle(ctx context.Context, user, slug string) (Article, error) {
+	article, err := a.repo.UnfavoriteArticle(ctx, slug, user)
+	if err != nil {
+		return Article{}, err
+	}
+	return a.forViewer(ctx, user, article)
+}
+
+func (a ArticleService) UpdateArticle(ctx context.Context, author, slug string, update ArticleUpdate) (Article, error) {
+	article, err := a.findAuthoredArticle(ctx, author, slug)
+	if err != nil {
+		return Article{}, err
+	}
+	if update.Title != nil {
+		article.Title = *update.Title
+		article.Slug = slugify(article.Title)
+	}
+	if update.Description != nil {
+		article.Description = *update.Description
+	}
+	if update.Body != nil {
+		article.Body = *update.Body
+	}
+	article.UpdatedAt = time.Now()
+	updated, err := retryWithSlugSuffix(article, func(article Article) (Article, error) {
+		return a.repo.UpdateArticle(ctx, slug, article)
+	})
+	if err != nil {
+		return Article{}, err
+	}
+	return a.forViewer(ctx, author, updated)
+}
+
+func (a ArticleService) DeleteArticle(ctx context.Context, author, slug string) error {
+	if _, err := a.findAuthoredArticle(ctx, author, slug); err != nil {
+		return err
+	}
+	return a.repo.DeleteArticle(ctx, slug)
+}
+
+func (a ArticleService) findAuthoredArticle(ctx context.Context, author, slug string) (Article, error) {
+	article, err := a.repo.FindArticleBySlug(ctx, slug)
+	if err != nil {
+		return Article{}, err
+	}
+	if article.AuthorID != author {
+		return Article{}, fmt.Errorf("%w: article %s is not written by %s", ErrForbidden, slug, author)
+	}
+	return article, nil
+}
+
+// forViewerAll resolves author profiles and favorited of articles like [ArticleService.forViewer],
+// looking up each of authors, follows and favorites at once rather than per article.
+func (a ArticleService) forViewerAll(ctx context.Context, viewer string, articles []Article, count int) ([]Article, int, error) {
+	if len(articles) == 0 {
+		return articles, count, nil
+	}
+	seen := make(map[string]bool, len(articles))
+	authorIDs := make([]string, 0, len(articles))
+	articleIDs := make([]int64, 0, len(articles))
+	for _, article := range articles {
+		if !seen[article.AuthorID] {
+			seen[article.AuthorID] = true
+			authorIDs = append(authorIDs, article.AuthorID)
+		}
+		articleIDs = append(articleIDs, article.ID)
+	}
+	authors, err := a.users.FindUsersByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	following, favorited := map[string]bool{}, map[int64]bool{}
+	if viewer != "" {
+		followees, err := a.follows.FolloweesAmong(ctx, viewer, authorIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, followee := range followees {
+			following[followee] = followee != viewer
+		}
+		ids, err := a.repo.FavoritedAmong(ctx, viewer, articleIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, id := range ids {
+			favorited[id] = true
+		}
+	}
+	profiles := make(map[string]Profile, len(authors))
+	for _, author := range authors {
+		author.Following = following[author.ID]
+		profiles[author.ID] = author.Profile
+	}
+	for i, article := range articles {
+		profile, ok := profiles[article.AuthorID]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w with id %s", ErrUserNotFound, article.AuthorID)
+		}
+		articles[i].Author = profile
+		articles[i].Favorited = favorited[article.ID]
+	}
+	return articles, count, nil
+}
+
+// forViewer resolves author profile and favorited of article for viewer ID, which is empty for anonymous viewer.
+func (a ArticleService) forViewer(ctx context.Context, viewer string, article Article) (Article, error) {
+	var err error
+	article.Author, err = profileByID(ctx, a.users, a.follows, viewer, article.AuthorID)
+	if err != nil {
+		return Article{}, err
+	}
+	if viewer == "" {
+		return article, nil
+	}
+	article.Favorited, err = a.repo.IsFavorited(ctx, article.Slug, viewer)
+	if err != nil {
+		return Article{}, err
+	}
+	return article, nil
+}
+
+// retryWithSlugSuffix retries save once with random suffix on slug when slug is already taken
+func retryWithSlugSuffix(article Article, save func(Article) (Article, error)) (Article, error) {
+	saved, err := save(article)
+	if errors.Is(err, ErrArticleAlreadyExists) {
+		article.Slug = article.Slug + "-" + randomSuffix()
+		return save(article)
+	}
+	return saved, err
+}
+
+// slugify converts title into lowercase words joined by hyphen, or random one if title has no words
+func slugify(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return randomSuffix()
+	}
+	return strings.Join(words, "-")
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
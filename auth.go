@@ -2,27 +2,47 @@ package realworld
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
 
 type UserAuthService struct {
-	repo       UserRepository
-	jwtService JWTService
+	repo          UserRepository
+	jwtService    JWTService
+	hasher        PasswordHasher
+	refreshTokens RefreshTokenRepository
+	revokedTokens RevokedTokenRepository
+	refreshTTL    time.Duration
 }
 
 type AuthenticatedUser struct {
 	User
 	Token string
+	// RefreshToken is issued along with Token except on [UserAuthService.Authenticate]
+	RefreshToken string
 }
 
-func NewUserAuthService(repo UserRepository, jwtService JWTService) UserAuthService {
-	return UserAuthService{repo: repo, jwtService: jwtService}
+func NewUserAuthService(repo UserRepository, jwtService JWTService, hasher PasswordHasher, refreshTokens RefreshTokenRepository, revokedTokens RevokedTokenRepository) UserAuthService {
+	return UserAuthService{
+		repo:          repo,
+		jwtService:    jwtService,
+		hasher:        hasher,
+		refreshTokens: refreshTokens,
+		revokedTokens: revokedTokens,
+		refreshTTL:    DefaultRefreshTokenTTL,
+	}
+}
+
+// WithRefreshTokenTTL returns copy of service issuing refresh tokens valid for ttl
+func (u UserAuthService) WithRefreshTokenTTL(ttl time.Duration) UserAuthService {
+	u.refreshTTL = ttl
+	return u
 }
 
 func (u UserAuthService) Login(ctx context.Context, email, password string) (AuthenticatedUser, error) {
@@ -30,29 +50,153 @@ func (u UserAuthService) Login(ctx context.Context, email, password string) (Aut
 	if err != nil {
 		return AuthenticatedUser{}, err
 	}
-	// TODO: Add password hashing
-	if user.Password != password {
+	matched, err := u.hasher.Verify(user.Password, password)
+	if err != nil {
+		return AuthenticatedUser{}, err
+	}
+	if !matched {
 		return AuthenticatedUser{}, fmt.Errorf("%w with email %s", ErrPasswordNotMatched, email)
 	}
-	token, err := u.jwtService.Serialize(JWTClaim{Email: email, Exp: time.Now().Add(time.Hour).Unix()})
+	// NOTE: password is only known here, so hash stored with outdated parameters is replaced on login
+	if u.hasher.NeedsRehash(user.Password) {
+		if user.Password, err = u.hasher.Hash(password); err != nil {
+			return AuthenticatedUser{}, err
+		}
+		if user, err = u.repo.UpdateUser(ctx, user); err != nil {
+			return AuthenticatedUser{}, err
+		}
+	}
+	return u.IssueToken(ctx, user)
+}
+
+// IssueToken returns user with a freshly signed token and refresh token of new family, e.g. on registration.
+func (u UserAuthService) IssueToken(ctx context.Context, user User) (AuthenticatedUser, error) {
+	family, err := newTokenFamily()
 	if err != nil {
 		return AuthenticatedUser{}, err
 	}
-	return AuthenticatedUser{User: user, Token: token}, nil
+	return u.issueTokens(ctx, user, family)
 }
 
-func (u UserAuthService) Authenticate(ctx context.Context, token string) (AuthenticatedUser, error) {
-	claim, err := u.jwtService.Deserialize(token)
+// Refresh rotates refreshToken into new one of the same family along with new token.
+// Refresh token used again after rotated is taken as leaked, and its whole family is revoked.
+func (u UserAuthService) Refresh(ctx context.Context, refreshToken string) (AuthenticatedUser, error) {
+	if refreshToken == "" {
+		return AuthenticatedUser{}, ErrTokenNotFound
+	}
+	stored, err := u.refreshTokens.UseRefreshToken(ctx, hashOpaqueToken(refreshToken))
+	if err != nil {
+		return AuthenticatedUser{}, err
+	}
+	if stored.Used {
+		if err := u.refreshTokens.RevokeTokenFamily(ctx, stored.Family); err != nil {
+			return AuthenticatedUser{}, err
+		}
+		return AuthenticatedUser{}, fmt.Errorf("%w reused, so every refresh token of its family is revoked", ErrInvalidRefreshToken)
+	}
+	if u.jwtService.now().After(stored.ExpiresAt) {
+		return AuthenticatedUser{}, fmt.Errorf("%w expired at %s", ErrInvalidRefreshToken, stored.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	user, err := u.repo.FindUserByID(ctx, stored.UserID)
+	if err != nil {
+		return AuthenticatedUser{}, err
+	}
+	if stored.TokenVersion != user.TokenVersion {
+		return AuthenticatedUser{}, fmt.Errorf("%w issued before logging out of all sessions", ErrInvalidRefreshToken)
+	}
+	return u.issueTokens(ctx, user, stored.Family)
+}
+
+func (u UserAuthService) issueTokens(ctx context.Context, user User, family string) (AuthenticatedUser, error) {
+	claim, err := u.jwtService.NewClaim(user.ID)
+	if err != nil {
+		return AuthenticatedUser{}, err
+	}
+	// NOTE: refresh token family identifies the session, so that logout revokes both tokens
+	claim.Sid = family
+	claim.Ver = user.TokenVersion
+	token, err := u.jwtService.Serialize(claim)
+	if err != nil {
+		return AuthenticatedUser{}, err
+	}
+	refreshToken, hash, err := newOpaqueToken()
 	if err != nil {
 		return AuthenticatedUser{}, err
 	}
-	user, err := u.repo.FindUserByEmail(ctx, claim.Email)
+	err = u.refreshTokens.CreateRefreshToken(ctx, RefreshToken{
+		Hash:         hash,
+		Family:       family,
+		UserID:       user.ID,
+		ExpiresAt:    u.jwtService.now().Add(u.refreshTTL),
+		TokenVersion: user.TokenVersion,
+	})
+	if err != nil {
+		return AuthenticatedUser{}, err
+	}
+	return AuthenticatedUser{User: user, Token: token, RefreshToken: refreshToken}, nil
+}
+
+func (u UserAuthService) Authenticate(ctx context.Context, token string) (AuthenticatedUser, error) {
+	_, user, err := u.authenticate(ctx, token)
 	if err != nil {
 		return AuthenticatedUser{}, err
 	}
 	return AuthenticatedUser{User: user, Token: token}, nil
 }
 
+// Logout revokes token and refresh tokens of its session,
+// or every token issued to the user so far if all is set.
+func (u UserAuthService) Logout(ctx context.Context, token string, all bool) error {
+	claim, user, err := u.authenticate(ctx, token)
+	if err != nil {
+		return err
+	}
+	if all {
+		return u.repo.IncrementTokenVersion(ctx, user.ID)
+	}
+	if claim.Jti == "" {
+		return fmt.Errorf("%w without jti cannot be revoked", ErrInvalidToken)
+	}
+	// NOTE: token is accepted for leeway after exp, so it is kept revoked that long too
+	expiresAt := time.Unix(claim.Exp, 0).Add(u.jwtService.leeway)
+	if err := u.revokedTokens.RevokeToken(ctx, claim.Jti, expiresAt); err != nil {
+		return err
+	}
+	if claim.Sid == "" {
+		return nil
+	}
+	return u.refreshTokens.RevokeTokenFamily(ctx, claim.Sid)
+}
+
+func (u UserAuthService) authenticate(ctx context.Context, token string) (JWTClaim, User, error) {
+	claim, err := u.jwtService.Deserialize(token)
+	if err != nil {
+		return JWTClaim{}, User{}, err
+	}
+	if claim.Jti != "" {
+		revoked, err := u.revokedTokens.IsTokenRevoked(ctx, claim.Jti)
+		if err != nil {
+			return JWTClaim{}, User{}, err
+		}
+		if revoked {
+			return JWTClaim{}, User{}, fmt.Errorf("%w by logout", ErrTokenRevoked)
+		}
+	}
+	user, err := u.repo.FindUserByID(ctx, claim.Sub)
+	if err != nil {
+		return JWTClaim{}, User{}, err
+	}
+	if claim.Ver != user.TokenVersion {
+		return JWTClaim{}, User{}, fmt.Errorf("%w by logout of all sessions", ErrTokenRevoked)
+	}
+	return claim, user, nil
+}
+
+// JWKS returns public keys verifiers of issued tokens need
+func (u UserAuthService) JWKS() JWKSet {
+	return u.jwtService.JWKS()
+}
+
 type JWTSerializer interface {
 	Header() string
 	Serialize(c JWTClaim) (string, error)
@@ -62,19 +206,153 @@ type JWTDeserializer interface {
 	Deserialize(token string) (JWTClaim, error)
 }
 
+// JWTClaim holds times as unix seconds. Nbf and Iat are checked only when set.
+// Sub is [User.ID], which stays the same when email of the user changes.
+// Sid identifies login session and Ver is [User.TokenVersion] when issued.
 type JWTClaim struct {
-	Email string `json:"email"`
-	Exp   int64  `json:"exp"`
+	Exp int64  `json:"exp"`
+	Nbf int64  `json:"nbf,omitempty"`
+	Iat int64  `json:"iat,omitempty"`
+	Iss string `json:"iss,omitempty"`
+	Sub string `json:"sub,omitempty"`
+	Aud string `json:"aud,omitempty"`
+	Jti string `json:"jti,omitempty"`
+	Sid string `json:"sid,omitempty"`
+	Ver int    `json:"ver,omitempty"`
 }
 
+const (
+	// DefaultJWTLeeway is clock skew between token issuer and verifier tolerated by default
+	DefaultJWTLeeway = 30 * time.Second
+	DefaultJWTTTL    = time.Hour
+)
+
 type JWTService struct {
 	header string
-	secret []byte
+	// keys holds every key not retired yet by kid, including active one to sign with
+	keys     map[string]jwtKey
+	active   string
+	now      func() time.Time
+	leeway   time.Duration
+	ttl      time.Duration
+	issuer   string
+	audience string
 }
 
+// NewJWTService returns service signing and verifying with HS256 using shared secret
 func NewJWTService(secret []byte) JWTService {
-	header := base64.URLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
-	return JWTService{header: header, secret: secret}
+	return newJWTService(hmacKey{secret: secret})
+}
+
+// NewJWTServiceFromPEM returns service signing with active and verifying with active or any of others,
+// so that keys are rotated by moving the active one into others until tokens signed with it expire.
+// Algorithm is picked from the type of PEM encoded key, which is RS256 for RSA,
+// ES256, ES384 or ES512 for ECDSA by curve and EdDSA for Ed25519.
+// Keys are identified by kid of their JWK thumbprint.
+// Service with public active key only verifies tokens and fails to serialize.
+func NewJWTServiceFromPEM(active []byte, others ...[]byte) (JWTService, error) {
+	key, err := parseJWTKeyPEM(active)
+	if err != nil {
+		return JWTService{}, err
+	}
+	j := newJWTService(key)
+	for _, data := range others {
+		key, err := parseJWTKeyPEM(data)
+		if err != nil {
+			return JWTService{}, err
+		}
+		j.keys[kidOf(key)] = key
+	}
+	return j, nil
+}
+
+func newJWTService(key jwtKey) JWTService {
+	kid := kidOf(key)
+	header, _ := json.Marshal(struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid,omitempty"`
+		Typ string `json:"typ"`
+	}{key.alg(), kid, "JWT"})
+	return JWTService{
+		header: base64.RawURLEncoding.EncodeToString(header),
+		keys:   map[string]jwtKey{kid: key},
+		active: kid,
+		now:    time.Now,
+		leeway: DefaultJWTLeeway,
+		ttl:    DefaultJWTTTL,
+	}
+}
+
+// kidOf returns JWK thumbprint of public key, or empty one for symmetric key
+func kidOf(key jwtKey) string {
+	jwk, ok := key.jwk()
+	if !ok {
+		return ""
+	}
+	return jwk.thumbprint()
+}
+
+// JWKS returns public keys to verify tokens with, which is empty for symmetric key
+func (j JWTService) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(j.keys))}
+	for kid, key := range j.keys {
+		jwk, ok := key.jwk()
+		if !ok {
+			continue
+		}
+		jwk.Kid, jwk.Alg, jwk.Use = kid, key.alg(), "sig"
+		set.Keys = append(set.Keys, jwk)
+	}
+	slices.SortFunc(set.Keys, func(a, b JWK) int { return strings.Compare(a.Kid, b.Kid) })
+	return set
+}
+
+// WithTTL returns copy of service issuing claims valid for ttl
+func (j JWTService) WithTTL(ttl time.Duration) JWTService {
+	j.ttl = ttl
+	return j
+}
+
+// WithIssuer returns copy of service issuing claims with iss and accepting only those with the same iss
+func (j JWTService) WithIssuer(issuer string) JWTService {
+	j.issuer = issuer
+	return j
+}
+
+// WithAudience returns copy of service issuing claims with aud and accepting only those with the same aud
+func (j JWTService) WithAudience(audience string) JWTService {
+	j.audience = audience
+	return j
+}
+
+// NewClaim returns claim for subject valid from now for ttl of service with unique jti
+func (j JWTService) NewClaim(subject string) (JWTClaim, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return JWTClaim{}, err
+	}
+	now := j.now()
+	return JWTClaim{
+		Exp: now.Add(j.ttl).Unix(),
+		Nbf: now.Unix(),
+		Iat: now.Unix(),
+		Iss: j.issuer,
+		Sub: subject,
+		Aud: j.audience,
+		Jti: hex.EncodeToString(jti),
+	}, nil
+}
+
+// WithClock returns copy of service reading current time from now, e.g. fixed one in tests
+func (j JWTService) WithClock(now func() time.Time) JWTService {
+	j.now = now
+	return j
+}
+
+// WithLeeway returns copy of service tolerating clock skew of leeway on validating times in claim
+func (j JWTService) WithLeeway(leeway time.Duration) JWTService {
+	j.leeway = leeway
+	return j
 }
 
 func (j JWTService) Serialize(c JWTClaim) (string, error) {
@@ -82,15 +360,13 @@ func (j JWTService) Serialize(c JWTClaim) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	payload := base64.URLEncoding.EncodeToString(claims)
+	payload := base64.RawURLEncoding.EncodeToString(claims)
 
-	h := hmac.New(sha256.New, j.secret)
-	if _, err := h.Write([]byte(j.header + "." + payload)); err != nil {
+	signature, err := j.keys[j.active].sign([]byte(j.header + "." + payload))
+	if err != nil {
 		return "", err
 	}
-
-	signature := base64.URLEncoding.EncodeToString(h.Sum(nil))
-	return j.header + "." + payload + "." + signature, nil
+	return j.header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(signature), nil
 }
 
 func (j JWTService) Header() string {
@@ -106,30 +382,37 @@ func (j JWTService) Deserialize(token string) (JWTClaim, error) {
 		return JWTClaim{}, fmt.Errorf("%w invalid token with %d parts: '%s'", ErrInvalidToken, len(parts), token)
 	}
 
-	if parts[0] != j.header {
-		return JWTClaim{}, fmt.Errorf("%w header want: %s got: %s", ErrInvalidToken, j.header, parts[0])
-	}
-	_, err := base64.URLEncoding.DecodeString(parts[0])
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
 	if err != nil {
 		return JWTClaim{}, fmt.Errorf("%w header", ErrInvalidToken)
 	}
+	var h struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(header, &h); err != nil {
+		return JWTClaim{}, fmt.Errorf("%w header: %w", ErrInvalidToken, err)
+	}
+	key, ok := j.keys[h.Kid]
+	if !ok {
+		return JWTClaim{}, fmt.Errorf("%w unknown or retired kid: %s", ErrInvalidToken, h.Kid)
+	}
+	// NOTE: alg is checked against the key of kid, never trusted to choose how to verify
+	if h.Alg != key.alg() {
+		return JWTClaim{}, fmt.Errorf("%w alg want: %s got: %s", ErrInvalidToken, key.alg(), h.Alg)
+	}
 
-	payload, err := base64.URLEncoding.DecodeString(parts[1])
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
 		return JWTClaim{}, fmt.Errorf("%w payload", ErrInvalidToken)
 	}
 
-	signature, err := base64.URLEncoding.DecodeString(parts[2])
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
 	if err != nil {
 		return JWTClaim{}, fmt.Errorf("%w signature", ErrInvalidToken)
 	}
 
-	h := hmac.New(sha256.New, j.secret)
-	if _, err := h.Write([]byte(parts[0] + "." + parts[1])); err != nil {
-		return JWTClaim{}, err
-	}
-	expectedSignature := h.Sum(nil)
-	if !hmac.Equal(signature, expectedSignature) {
+	if !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
 		return JWTClaim{}, fmt.Errorf("%w signature mismatch", ErrInvalidToken)
 	}
 
@@ -137,5 +420,31 @@ func (j JWTService) Deserialize(token string) (JWTClaim, error) {
 	if err := json.Unmarshal(payload, &claim); err != nil {
 		return JWTClaim{}, err
 	}
+	if claim.Iss != j.issuer {
+		return JWTClaim{}, fmt.Errorf("%w issuer want: %s got: %s", ErrInvalidToken, j.issuer, claim.Iss)
+	}
+	if claim.Aud != j.audience {
+		return JWTClaim{}, fmt.Errorf("%w audience want: %s got: %s", ErrInvalidToken, j.audience, claim.Aud)
+	}
+	if err := j.validateTimes(claim); err != nil {
+		return JWTClaim{}, err
+	}
 	return claim, nil
 }
+
+func (j JWTService) validateTimes(claim JWTClaim) error {
+	now := j.now()
+	if claim.Exp == 0 {
+		return fmt.Errorf("%w without exp", ErrInvalidToken)
+	}
+	if exp := time.Unix(claim.Exp, 0); now.After(exp.Add(j.leeway)) {
+		return fmt.Errorf("%w at %s", ErrTokenExpired, exp.UTC().Format(time.RFC3339))
+	}
+	if nbf := time.Unix(claim.Nbf, 0); claim.Nbf != 0 && now.Before(nbf.Add(-j.leeway)) {
+		return fmt.Errorf("%w not valid before %s", ErrInvalidToken, nbf.UTC().Format(time.RFC3339))
+	}
+	if iat := time.Unix(claim.Iat, 0); claim.Iat != 0 && now.Before(iat.Add(-j.leeway)) {
+		return fmt.Errorf("%w issued in the future at %s", ErrInvalidToken, iat.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
@@ -1,16 +1,29 @@
 package realworld_test
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/carlmjohnson/be"
 	"github.com/raeperd/realworld"
+	"github.com/raeperd/realworld/internal/inmemory"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestAuth(t *testing.T) {
-	claim := realworld.JWTClaim{Email: "user@email.com", Exp: time.Now().Unix()}
+	claim := realworld.JWTClaim{Sub: "user-id", Exp: time.Now().Unix()}
 	service := realworld.NewJWTService([]byte("secret"))
 
 	token, err := service.Serialize(claim)
@@ -26,3 +39,210 @@ func TestAuth(t *testing.T) {
 	be.NilErr(t, err)
 	be.Equal(t, token, token2)
 }
+
+func TestAuthTimes(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	leeway := 10 * time.Second
+	issuer := realworld.NewJWTService([]byte("secret"))
+	verifier := issuer.WithClock(func() time.Time { return now }).WithLeeway(leeway)
+	at := func(d time.Duration) int64 { return now.Add(d).Unix() }
+
+	testcases := map[string]struct {
+		claim realworld.JWTClaim
+		want  error
+	}{
+		"valid":                {realworld.JWTClaim{Exp: at(time.Hour), Nbf: at(0), Iat: at(0)}, nil},
+		"expired in leeway":    {realworld.JWTClaim{Exp: at(-leeway)}, nil},
+		"expired":              {realworld.JWTClaim{Exp: at(-leeway - time.Second)}, realworld.ErrTokenExpired},
+		"without exp":          {realworld.JWTClaim{}, realworld.ErrInvalidToken},
+		"not before in leeway": {realworld.JWTClaim{Exp: at(time.Hour), Nbf: at(leeway)}, nil},
+		"not before":           {realworld.JWTClaim{Exp: at(time.Hour), Nbf: at(leeway + time.Second)}, realworld.ErrInvalidToken},
+		"issued in future":     {realworld.JWTClaim{Exp: at(time.Hour), Iat: at(leeway + time.Second)}, realworld.ErrInvalidToken},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			token, err := issuer.Serialize(tc.claim)
+			be.NilErr(t, err)
+
+			_, err = verifier.Deserialize(token)
+			if tc.want == nil {
+				be.NilErr(t, err)
+				return
+			}
+			be.True(t, errors.Is(err, tc.want))
+		})
+	}
+	be.Equal(t, 401, realworld.StatusFromError(realworld.ErrTokenExpired))
+}
+
+func TestAuthClaims(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	service := realworld.NewJWTService([]byte("secret")).
+		WithClock(func() time.Time { return now }).
+		WithTTL(10 * time.Minute).WithIssuer("realworld").WithAudience("web")
+
+	claim, err := service.NewClaim("user-id")
+	be.NilErr(t, err)
+	be.Equal(t, "user-id", claim.Sub)
+	be.Equal(t, "realworld", claim.Iss)
+	be.Equal(t, "web", claim.Aud)
+	be.Equal(t, now.Add(10*time.Minute).Unix(), claim.Exp)
+	be.Nonzero(t, claim.Jti)
+
+	other, err := service.NewClaim("user-id")
+	be.NilErr(t, err)
+	be.Unequal(t, claim.Jti, other.Jti)
+
+	token, err := service.Serialize(claim)
+	be.NilErr(t, err)
+	after, err := service.Deserialize(token)
+	be.NilErr(t, err)
+	be.Equal(t, claim, after)
+
+	_, err = service.WithIssuer("other").Deserialize(token)
+	be.True(t, errors.Is(err, realworld.ErrInvalidToken))
+	_, err = service.WithAudience("mobile").Deserialize(token)
+	be.True(t, errors.Is(err, realworld.ErrInvalidToken))
+}
+
+func TestAuthAsymmetric(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	be.NilErr(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	be.NilErr(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	be.NilErr(t, err)
+
+	testcases := map[string]struct {
+		private crypto.Signer
+		alg     string
+	}{
+		"RS256": {rsaKey, "RS256"},
+		"ES256": {ecdsaKey, "ES256"},
+		"EdDSA": {ed25519Key, "EdDSA"},
+	}
+	claim := realworld.JWTClaim{Sub: "user-id", Exp: time.Now().Add(time.Hour).Unix()}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			publicDER, err := x509.MarshalPKIXPublicKey(tc.private.Public())
+			be.NilErr(t, err)
+			publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+			signer, err := realworld.NewJWTServiceFromPEM(privatePEM(t, tc.private))
+			be.NilErr(t, err)
+			verifier, err := realworld.NewJWTServiceFromPEM(publicPEM)
+			be.NilErr(t, err)
+
+			token, err := signer.Serialize(claim)
+			be.NilErr(t, err)
+			be.In(t, `"alg":"`+tc.alg+`"`, decodeHeader(t, token))
+
+			after, err := verifier.Deserialize(token)
+			be.NilErr(t, err)
+			be.Equal(t, claim, after)
+
+			_, err = verifier.Serialize(claim)
+			be.Nonzero(t, err)
+
+			// NOTE: HS256 token signed with the public key as secret must not pass as asymmetric one
+			forged, err := realworld.NewJWTService(publicPEM).Serialize(claim)
+			be.NilErr(t, err)
+			_, err = verifier.Deserialize(forged)
+			be.True(t, errors.Is(err, realworld.ErrInvalidToken))
+		})
+	}
+}
+
+// TestAuthCompactSerialization verifies token as any JWS verifier does, which requires unpadded base64url
+func TestAuthCompactSerialization(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	be.NilErr(t, err)
+	signer, err := realworld.NewJWTServiceFromPEM(privatePEM(t, private))
+	be.NilErr(t, err)
+	token, err := signer.Serialize(realworld.JWTClaim{Sub: "user-id", Exp: time.Now().Add(time.Hour).Unix()})
+	be.NilErr(t, err)
+	be.False(t, strings.Contains(token, "="))
+
+	parts := strings.Split(token, ".")
+	be.Equal(t, 3, len(parts))
+	signature, err := base64.RawURLEncoding.Strict().DecodeString(parts[2])
+	be.NilErr(t, err)
+	be.True(t, ed25519.Verify(public, []byte(parts[0]+"."+parts[1]), signature))
+	payload, err := base64.RawURLEncoding.Strict().DecodeString(parts[1])
+	be.NilErr(t, err)
+	be.In(t, `"sub":"user-id"`, string(payload))
+}
+
+func decodeHeader(t *testing.T, token string) string {
+	header, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	be.NilErr(t, err)
+	return string(header)
+}
+
+func TestAuthKeyRotation(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	be.NilErr(t, err)
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	be.NilErr(t, err)
+	oldPEM, newPEM := privatePEM(t, oldKey), privatePEM(t, newKey)
+	claim := realworld.JWTClaim{Sub: "user-id", Exp: time.Now().Add(time.Hour).Unix()}
+
+	before, err := realworld.NewJWTServiceFromPEM(oldPEM)
+	be.NilErr(t, err)
+	oldToken, err := before.Serialize(claim)
+	be.NilErr(t, err)
+	be.Equal(t, 1, len(before.JWKS().Keys))
+	be.In(t, `"kid":"`+before.JWKS().Keys[0].Kid+`"`, decodeHeader(t, oldToken))
+
+	rotated, err := realworld.NewJWTServiceFromPEM(newPEM, oldPEM)
+	be.NilErr(t, err)
+	newToken, err := rotated.Serialize(claim)
+	be.NilErr(t, err)
+	for _, token := range []string{oldToken, newToken} {
+		_, err = rotated.Deserialize(token)
+		be.NilErr(t, err)
+	}
+	jwks := rotated.JWKS()
+	be.Equal(t, 2, len(jwks.Keys))
+	for _, key := range jwks.Keys {
+		be.Equal(t, "sig", key.Use)
+		be.Nonzero(t, key.Kid)
+	}
+
+	retired, err := realworld.NewJWTServiceFromPEM(newPEM)
+	be.NilErr(t, err)
+	_, err = retired.Deserialize(newToken)
+	be.NilErr(t, err)
+	_, err = retired.Deserialize(oldToken)
+	be.True(t, errors.Is(err, realworld.ErrInvalidToken))
+}
+
+func privatePEM(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	be.NilErr(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestAuthLoginRehash(t *testing.T) {
+	ctx := context.Background()
+	users := inmemory.NewUserRepository()
+	bcryptHash, err := realworld.NewBcryptHasher(bcrypt.MinCost).Hash("password")
+	be.NilErr(t, err)
+	_, err = users.CreateUser(ctx, realworld.User{ID: "user-id", Profile: realworld.Profile{Username: "user"}, Email: "user@email.com", Password: bcryptHash})
+	be.NilErr(t, err)
+	params := realworld.Argon2idParams{Memory: 1024, Iterations: 1, Threads: 1, SaltLength: 16, KeyLength: 32}
+	hasher := realworld.NewMigratingHasher(realworld.NewArgon2idHasher(params))
+	auth := realworld.NewUserAuthService(users, realworld.NewJWTService([]byte("secret")), hasher,
+		inmemory.NewRefreshTokenRepository(time.Now), inmemory.NewRevokedTokenRepository(time.Now))
+
+	_, err = auth.Login(ctx, "user@email.com", "password")
+	be.NilErr(t, err)
+	user, err := users.FindUserByID(ctx, "user-id")
+	be.NilErr(t, err)
+	be.True(t, strings.HasPrefix(user.Password, "$argon2id$"))
+
+	_, err = auth.Login(ctx, "user@email.com", "password")
+	be.NilErr(t, err)
+	_, err = auth.Login(ctx, "user@email.com", "wrong-password")
+	be.True(t, errors.Is(err, realworld.ErrPasswordNotMatched))
+}
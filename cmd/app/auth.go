@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/raeperd/realworld"
+)
+
+const (
+	sessionCookieName = "session"
+	// csrfCookieName is readable from script to be sent back in csrfHeaderName, which cross-site requests cannot do
+	csrfCookieName = "csrf_token"
+	csrfHeaderName = "X-CSRF-Token"
+)
+
+// tokenFromRequest returns token from Authorization header of Token or Bearer scheme in any case,
+// or from session cookie guarded by double-submit CSRF token. It returns empty token without either.
+func tokenFromRequest(r *http.Request) (string, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, _ := strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, "Token") && !strings.EqualFold(scheme, "Bearer") {
+			return "", fmt.Errorf("%w: authorization scheme must be Token or Bearer", realworld.ErrTokenNotFound)
+		}
+		if token == "" || strings.ContainsAny(token, " \t") {
+			return "", fmt.Errorf("%w: malformed authorization header", realworld.ErrTokenNotFound)
+		}
+		return token, nil
+	}
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return "", nil
+	}
+	if err := checkCSRF(r); err != nil {
+		return "", err
+	}
+	return cookie.Value, nil
+}
+
+// checkCSRF requires request changing state to carry CSRF token same as its cookie
+func checkCSRF(r *http.Request) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+	cookie, err := r.Cookie(csrfCookieName)
+	if err != nil {
+		return fmt.Errorf("%w: csrf cookie required along with session cookie", realworld.ErrForbidden)
+	}
+	header := r.Header.Get(csrfHeaderName)
+	if header == "" || subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) != 1 {
+		return fmt.Errorf("%w: %s header must match csrf cookie", realworld.ErrForbidden, csrfHeaderName)
+	}
+	return nil
+}
+
+type userContextKey struct{}
+
+// RequireAuth returns middleware responding with error unless request carries valid token,
+// which puts the authenticated user into request context for [MustUserFromContext].
+func RequireAuth(auth realworld.UserAuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := tokenFromRequest(r)
+			if err != nil {
+				_ = encodeError(w, r, err)
+				return
+			}
+			user, err := auth.Authenticate(r.Context(), token)
+			if err != nil {
+				_ = encodeError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userContextKey{}, user)))
+		})
+	}
+}
+
+// OptionalAuth returns middleware like [RequireAuth] but passing request without token as anonymous.
+// Request with invalid token is still rejected rather than taken as anonymous.
+func OptionalAuth(auth realworld.UserAuthService) func(http.Handler) http.Handler {
+	require := RequireAuth(auth)
+	return func(next http.Handler) http.Handler {
+		authenticated := require(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token, err := tokenFromRequest(r); err == nil && token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UserFromContext returns user put by [RequireAuth] or [OptionalAuth], or false for anonymous request
+func UserFromContext(ctx context.Context) (realworld.AuthenticatedUser, bool) {
+	user, ok := ctx.Value(userContextKey{}).(realworld.AuthenticatedUser)
+	return user, ok
+}
+
+// MustUserFromContext returns user put by [RequireAuth], panicking on route registered without it
+func MustUserFromContext(ctx context.Context) realworld.AuthenticatedUser {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		panic("MustUserFromContext called on route without RequireAuth")
+	}
+	return user
+}
+
+// ViewerFromContext returns id of user put by [OptionalAuth], or empty string for anonymous request
+func ViewerFromContext(ctx context.Context) string {
+	user, _ := UserFromContext(ctx)
+	return user.ID
+}
+
+func setSessionCookies(w http.ResponseWriter, token string) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(b),
+		Path:     "/",
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func clearSessionCookies(w http.ResponseWriter) {
+	for _, name := range []string{sessionCookieName, csrfCookieName} {
+		http.SetCookie(w, &http.Cookie{Name: name, Path: "/", MaxAge: -1, Secure: true, SameSite: http.SameSiteLaxMode})
+	}
+}
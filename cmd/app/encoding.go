@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/raeperd/realworld"
 )
@@ -18,7 +22,11 @@ func decode[T RequestBody](r *http.Request) (T, error) {
 }
 
 func encode[T any](w http.ResponseWriter, status int, v T) error {
-	w.Header().Set("Content-Type", "application/json")
+	return encodeContent(w, "application/json", status, v)
+}
+
+func encodeContent[T any](w http.ResponseWriter, contentType string, status int, v T) error {
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
 		return fmt.Errorf("encode json: %w", err)
@@ -26,38 +34,118 @@ func encode[T any](w http.ResponseWriter, status int, v T) error {
 	return nil
 }
 
-func encodeError(w http.ResponseWriter, err error) error {
-	if errors, ok := err.(interface{ Unwrap() []error }); ok {
-		return encode(w, realworld.StatusFromError(err), NewErrorResponseBody(errors.Unwrap()...))
+// encodeError writes err as [ProblemDetails] if request prefers application/problem+json,
+// or as [ErrorResponseBody] of RealWorld spec otherwise
+func encodeError(w http.ResponseWriter, r *http.Request, err error) error {
+	if acceptsProblem(r.Header.Get("Accept")) {
+		problem := NewProblemDetails(r, err)
+		return encodeContent(w, "application/problem+json", problem.Status, problem)
 	}
-	return encode(w, realworld.StatusFromError(err), NewErrorResponseBody(err))
+	return encode(w, realworld.StatusFromError(err), NewErrorResponseBody(unwrapJoined(err)...))
+}
+
+// acceptsProblem reports whether accept header weighs application/problem+json at least as application/json.
+// Wildcards are not counted, so that clients not asking for problem details explicitly keep legacy format.
+func acceptsProblem(accept string) bool {
+	var problem, legacy float64
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(value)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case "application/problem+json":
+			problem = q
+		case "application/json":
+			legacy = q
+		}
+	}
+	return 0 < problem && legacy <= problem
+}
+
+// unwrapJoined returns errors joined by [errors.Join], or err itself if not joined
+func unwrapJoined(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
 }
 
 type RequestBody interface {
-	PostUserRequestBody | PostUserLoginRequestBody
+	PostUserRequestBody | PostUserLoginRequestBody | PutUserRequestBody | RefreshTokenRequestBody |
+		PostArticleRequestBody | PutArticleRequestBody | PostCommentRequestBody
 }
 
 // TODO: remove this and embed response inside http.Handler
 type ResponseBody interface {
-	ErrorResponseBody | PostUserResponseBody | HealthCheckResponse
+	ErrorResponseBody | ProblemDetails | PostUserResponseBody | HealthCheckResponse
 }
 
+// ErrorResponseBody holds error messages by field, or under body if not caused by a field
 type ErrorResponseBody struct {
-	Errors struct {
-		Body []string `json:"body"`
-	} `json:"errors"`
+	Errors map[string][]string `json:"errors"`
 }
 
-func NewErrorResponseBody(errors ...error) ErrorResponseBody {
-	responses := make([]string, len(errors))
-	for i, err := range errors {
-		responses[i] = err.Error()
+func NewErrorResponseBody(errs ...error) ErrorResponseBody {
+	responses := make(map[string][]string)
+	for _, err := range errs {
+		var fieldErr realworld.FieldError
+		if errors.As(err, &fieldErr) {
+			responses[fieldErr.Field] = append(responses[fieldErr.Field], fieldErr.Message)
+			continue
+		}
+		responses["body"] = append(responses["body"], err.Error())
 	}
-	return ErrorResponseBody{
-		Errors: struct {
-			Body []string `json:"body"`
-		}{responses},
+	return ErrorResponseBody{Errors: responses}
+}
+
+// ProblemDetails is problem details of RFC 9457 built from [realworld.Error] that err wraps.
+// Type is "about:blank" for error not wrapping any, whose title is then the HTTP status text.
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// InvalidParams holds [realworld.FieldError], as in the example of RFC 9457
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// problemTypePrefix prefixes type of [ProblemDetails] followed by [realworld.Error] in kebab case, e.g. user-not-found
+const problemTypePrefix = "urn:realworld:problem:"
+
+func NewProblemDetails(r *http.Request, err error) ProblemDetails {
+	status := realworld.StatusFromError(err)
+	problem := ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: r.URL.Path,
+	}
+	var sentinel realworld.Error
+	if errors.As(err, &sentinel) {
+		problem.Type = problemTypePrefix + strings.ReplaceAll(string(sentinel), " ", "-")
+		problem.Title = strings.ToUpper(string(sentinel[:1])) + string(sentinel[1:])
 	}
+	for _, err := range unwrapJoined(err) {
+		var fieldErr realworld.FieldError
+		if errors.As(err, &fieldErr) {
+			problem.InvalidParams = append(problem.InvalidParams, InvalidParam{Name: fieldErr.Field, Reason: fieldErr.Message})
+		}
+	}
+	return problem
 }
 
 type PostUserRequestBody UserWrapper[PostUserRequest]
@@ -66,13 +154,28 @@ type PostUserResponseBody UserWrapper[PostUserResponse]
 
 type PostUserLoginRequestBody UserWrapper[PostUserLoginRequest]
 
+type PutUserRequestBody UserWrapper[PutUserRequest]
+
+type RefreshTokenRequestBody UserWrapper[RefreshTokenRequest]
+
 type GetProfilesResponseBody struct {
-	Profile struct {
-		Username  string  `json:"username"`
-		Bio       string  `json:"bio"`
-		Image     *string `json:"image"`
-		Following bool    `json:"following"`
-	} `json:"profile"`
+	Profile ProfileResponse `json:"profile"`
+}
+
+type ProfileResponse struct {
+	Username  string  `json:"username"`
+	Bio       string  `json:"bio"`
+	Image     *string `json:"image"`
+	Following bool    `json:"following"`
+}
+
+func newProfileResponse(profile realworld.Profile) ProfileResponse {
+	return ProfileResponse{
+		Username:  profile.Username,
+		Bio:       profile.Bio,
+		Image:     &profile.Image,
+		Following: profile.Following,
+	}
 }
 
 // TODO: Remove this struct by implementing [json.Marshaler]
@@ -80,6 +183,14 @@ type UserWrapper[T any] struct {
 	User T `json:"user"`
 }
 
+type ArticleWrapper[T any] struct {
+	Article T `json:"article"`
+}
+
+type CommentWrapper[T any] struct {
+	Comment T `json:"comment"`
+}
+
 func (r PostUserRequestBody) toUser() realworld.User {
 	return realworld.User{
 		Profile: realworld.Profile{
@@ -90,22 +201,22 @@ func (r PostUserRequestBody) toUser() realworld.User {
 	}
 }
 
-// TODO: Add detailed validation such as email format, password strength etc
 func (r PostUserRequestBody) Valid() error {
 	return errors.Join(
-		realworld.ErrorIfEmpty("username", r.User.Name),
-		realworld.ErrorIfEmpty("email", r.User.Email),
-		realworld.ErrorIfEmpty("password", r.User.Password),
+		realworld.ErrorIfInvalid("username", r.User.Name, realworld.UsernameRules...),
+		realworld.ErrorIfInvalid("email", r.User.Email, realworld.EmailRules...),
+		realworld.ErrorIfInvalid("password", r.User.Password, realworld.PasswordRules...),
 	)
 }
 
 func newPostUserResponseBody(user realworld.AuthenticatedUser) PostUserResponseBody {
 	return PostUserResponseBody{User: PostUserResponse{
-		Name:  user.Profile.Username,
-		Email: user.Email,
-		Token: user.Token,
-		Bio:   "",
-		Image: new(string)},
+		Name:         user.Profile.Username,
+		Email:        user.Email,
+		Token:        user.Token,
+		RefreshToken: user.RefreshToken,
+		Bio:          user.Bio,
+		Image:        &user.Image},
 	}
 }
 
@@ -117,6 +228,37 @@ func (r PostUserLoginRequestBody) Valid() error {
 
 }
 
+func (r PutUserRequestBody) toUserUpdate() realworld.UserUpdate {
+	return realworld.UserUpdate{
+		Email:    r.User.Email,
+		Username: r.User.Name,
+		Password: r.User.Password,
+		Bio:      r.User.Bio,
+		Image:    r.User.Image,
+	}
+}
+
+func (r PutUserRequestBody) Valid() error {
+	return errors.Join(
+		errorIfSetInvalid("username", r.User.Name, realworld.UsernameRules...),
+		errorIfSetInvalid("email", r.User.Email, realworld.EmailRules...),
+		errorIfSetInvalid("password", r.User.Password, realworld.PasswordRules...),
+		errorIfSetInvalid("image", r.User.Image, realworld.ImageRules...),
+	)
+}
+
+func (r RefreshTokenRequestBody) Valid() error {
+	return realworld.ErrorIfEmpty("refreshToken", r.User.RefreshToken)
+}
+
+// errorIfSetInvalid allows optional field to be omitted but not to be set violating rules
+func errorIfSetInvalid(name string, value *string, rules ...realworld.Rule) error {
+	if value == nil {
+		return nil
+	}
+	return realworld.ErrorIfInvalid(name, *value, rules...)
+}
+
 type PostUserRequest struct {
 	Name     string `json:"username"`
 	Email    string `json:"email"`
@@ -124,11 +266,12 @@ type PostUserRequest struct {
 }
 
 type PostUserResponse struct {
-	Name  string  `json:"username"`
-	Email string  `json:"email"`
-	Token string  `json:"token"`
-	Bio   string  `json:"bio"`
-	Image *string `json:"image"`
+	Name         string  `json:"username"`
+	Email        string  `json:"email"`
+	Token        string  `json:"token"`
+	RefreshToken string  `json:"refreshToken,omitempty"`
+	Bio          string  `json:"bio"`
+	Image        *string `json:"image"`
 }
 
 type PostUserLoginRequest struct {
@@ -136,6 +279,161 @@ type PostUserLoginRequest struct {
 	Password string `json:"password"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type PutUserRequest struct {
+	Name     *string `json:"username"`
+	Email    *string `json:"email"`
+	Password *string `json:"password"`
+	Bio      *string `json:"bio"`
+	Image    *string `json:"image"`
+}
+
+type PostArticleRequestBody ArticleWrapper[PostArticleRequest]
+
+type PutArticleRequestBody ArticleWrapper[PutArticleRequest]
+
+type ArticleResponseBody ArticleWrapper[ArticleResponse]
+
+func (r PostArticleRequestBody) toArticle() realworld.Article {
+	return realworld.Article{
+		Title:       r.Article.Title,
+		Description: r.Article.Description,
+		Body:        r.Article.Body,
+		TagList:     r.Article.TagList,
+	}
+}
+
+func (r PostArticleRequestBody) Valid() error {
+	return errors.Join(
+		realworld.ErrorIfEmpty("title", r.Article.Title),
+		realworld.ErrorIfEmpty("description", r.Article.Description),
+		realworld.ErrorIfEmpty("body", r.Article.Body),
+	)
+}
+
+func (r PutArticleRequestBody) toArticleUpdate() realworld.ArticleUpdate {
+	return realworld.ArticleUpdate{
+		Title:       r.Article.Title,
+		Description: r.Article.Description,
+		Body:        r.Article.Body,
+	}
+}
+
+func (r PutArticleRequestBody) Valid() error {
+	return errors.Join(
+		errorIfSetInvalid("title", r.Article.Title, realworld.Required),
+		errorIfSetInvalid("description", r.Article.Description, realworld.Required),
+		errorIfSetInvalid("body", r.Article.Body, realworld.Required),
+	)
+}
+
+func newArticleResponse(article realworld.Article) ArticleResponse {
+	tagList := article.TagList
+	if tagList == nil {
+		tagList = []string{}
+	}
+	return ArticleResponse{
+		Slug:           article.Slug,
+		Title:          article.Title,
+		Description:    article.Description,
+		Body:           article.Body,
+		TagList:        tagList,
+		CreatedAt:      article.CreatedAt,
+		UpdatedAt:      article.UpdatedAt,
+		Favorited:      article.Favorited,
+		FavoritesCount: article.FavoritesCount,
+		Author:         newProfileResponse(article.Author),
+	}
+}
+
+type ArticlesResponseBody struct {
+	Articles      []ArticleResponse `json:"articles"`
+	ArticlesCount int               `json:"articlesCount"`
+}
+
+func newArticlesResponseBody(articles []realworld.Article, count int) ArticlesResponseBody {
+	responses := make([]ArticleResponse, len(articles))
+	for i, article := range articles {
+		responses[i] = newArticleResponse(article)
+	}
+	return ArticlesResponseBody{Articles: responses, ArticlesCount: count}
+}
+
+type PostArticleRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Body        string   `json:"body"`
+	TagList     []string `json:"tagList"`
+}
+
+type PutArticleRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Body        *string `json:"body"`
+}
+
+type ArticleResponse struct {
+	Slug           string          `json:"slug"`
+	Title          string          `json:"title"`
+	Description    string          `json:"description"`
+	Body           string          `json:"body"`
+	TagList        []string        `json:"tagList"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+	Favorited      bool            `json:"favorited"`
+	FavoritesCount int             `json:"favoritesCount"`
+	Author         ProfileResponse `json:"author"`
+}
+
+type PostCommentRequestBody CommentWrapper[PostCommentRequest]
+
+type CommentResponseBody CommentWrapper[CommentResponse]
+
+type CommentsResponseBody struct {
+	Comments []CommentResponse `json:"comments"`
+}
+
+func (r PostCommentRequestBody) Valid() error {
+	return realworld.ErrorIfEmpty("body", r.Comment.Body)
+}
+
+func newCommentResponse(comment realworld.Comment) CommentResponse {
+	return CommentResponse{
+		ID:        comment.ID,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+		Author:    newProfileResponse(comment.Author),
+	}
+}
+
+func newCommentsResponseBody(comments []realworld.Comment) CommentsResponseBody {
+	responses := make([]CommentResponse, len(comments))
+	for i, comment := range comments {
+		responses[i] = newCommentResponse(comment)
+	}
+	return CommentsResponseBody{Comments: responses}
+}
+
+type PostCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type CommentResponse struct {
+	ID        int64           `json:"id"`
+	Body      string          `json:"body"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Author    ProfileResponse `json:"author"`
+}
+
+type TagsResponseBody struct {
+	Tags []string `json:"tags"`
+}
+
 type HealthCheckResponse struct {
 	BuildId             string
 	LastCommitHash      string
@@ -10,17 +10,23 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/carlmjohnson/versioninfo"
 	"github.com/raeperd/realworld"
-	"github.com/raeperd/realworld/internal/inmemory"
 )
 
 func main() {
 	ctx := context.Background()
-	if err := run(ctx, os.Stdout, os.Args); err != nil {
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrate(ctx, os.Stdout, os.Args[1:])
+	} else {
+		err = run(ctx, os.Stdout, os.Args)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
 	}
@@ -31,13 +37,31 @@ func run(ctx context.Context, w io.Writer, args []string) error {
 	defer cancel()
 
 	var (
-		port   uint
-		secret string
+		port     uint
+		secret   string
+		keyFile  string
+		oldKeys  string
+		leeway   time.Duration
+		ttl      time.Duration
+		refresh  time.Duration
+		issuer   string
+		audience string
+		cookie   bool
+		db       database
 	)
 	fs := flag.NewFlagSet("realworld", flag.ContinueOnError)
 	fs.SetOutput(w)
 	fs.UintVar(&port, "port", 8080, "port to use in http server")
 	fs.StringVar(&secret, "secret", "realworld-secret", "secret to use in JWT signing")
+	fs.StringVar(&keyFile, "jwt-key", "", "path to PEM encoded RSA, ECDSA or Ed25519 private key to sign JWT with instead of secret")
+	fs.StringVar(&oldKeys, "jwt-verify-keys", "", "comma separated paths to PEM encoded keys rotated out of jwt-key but still accepted in JWT validation")
+	fs.DurationVar(&leeway, "jwt-leeway", realworld.DefaultJWTLeeway, "clock skew tolerated in JWT validation")
+	fs.DurationVar(&ttl, "jwt-ttl", realworld.DefaultJWTTTL, "lifetime of issued JWT")
+	fs.DurationVar(&refresh, "refresh-ttl", realworld.DefaultRefreshTokenTTL, "lifetime of issued refresh token, renewed on every refresh")
+	fs.StringVar(&issuer, "jwt-issuer", "realworld", "issuer of JWT, which verified JWT must match")
+	fs.StringVar(&audience, "jwt-audience", "realworld", "audience of JWT, which verified JWT must match")
+	fs.BoolVar(&cookie, "session-cookie", false, "set HttpOnly session cookie with CSRF token on login for browser clients")
+	db.addFlags(fs)
 	versioninfo.AddFlag(fs)
 	fs.Usage = func() {
 		fmt.Fprintf(w, "Usage of %s:\n", fs.Name())
@@ -50,13 +74,43 @@ func run(ctx context.Context, w io.Writer, args []string) error {
 		return err
 	}
 
-	userRepository := inmemory.NewUserRepository()
-	userService := realworld.NewUserService(userRepository)
-	authService := realworld.NewUserAuthService(userRepository, realworld.NewJWTService([]byte(secret)))
+	repos, closeDB, err := db.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	hasher := realworld.NewMigratingHasher(realworld.NewArgon2idHasher(realworld.DefaultArgon2idParams))
+	userService := realworld.NewUserService(repos.users, repos.follows, hasher)
+	jwtService := realworld.NewJWTService([]byte(secret))
+	if keyFile != "" {
+		active, err := os.ReadFile(keyFile)
+		if err != nil {
+			return err
+		}
+		var others [][]byte
+		for _, path := range strings.FieldsFunc(oldKeys, func(r rune) bool { return r == ',' }) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			others = append(others, data)
+		}
+		if jwtService, err = realworld.NewJWTServiceFromPEM(active, others...); err != nil {
+			return err
+		}
+	}
+	jwtService = jwtService.WithLeeway(leeway).WithTTL(ttl).WithIssuer(issuer).WithAudience(audience)
+	authService := realworld.NewUserAuthService(repos.users, jwtService, hasher,
+		repos.refreshTokens, repos.revokedTokens).
+		WithRefreshTokenTTL(refresh)
+	articleService := realworld.NewArticleService(repos.articles, repos.users, repos.follows)
+	commentService := realworld.NewCommentService(repos.comments, repos.articles, repos.users, repos.follows)
+	tagService := realworld.NewTagService(repos.tags)
 
 	httpServer := &http.Server{
 		Addr:    ":" + strconv.Itoa(int(port)),
-		Handler: newServer(userService, authService),
+		Handler: newServer(userService, authService, articleService, commentService, tagService, cookie),
 	}
 	go func() {
 		// TODO: Use slog
@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/carlmjohnson/be"
 	"github.com/carlmjohnson/requests"
+	"github.com/raeperd/realworld"
 )
 
 func TestRun(t *testing.T) {
@@ -18,7 +23,7 @@ func TestRun(t *testing.T) {
 	t.Cleanup(cancel)
 	port := getFreePort(t)
 	go func() {
-		err := run(ctx, os.Stdout, []string{"realworld", "--port", port})
+		err := run(ctx, os.Stdout, []string{"realworld", "--port", port, "--session-cookie"})
 		if err != nil {
 			fmt.Printf("failed to run in test %s\n", err)
 		}
@@ -37,6 +42,15 @@ func TestRun(t *testing.T) {
 		be.Nonzero(t, res.LastCommitTimestamp)
 	})
 
+	t.Run("GET /.well-known/jwks.json", func(t *testing.T) {
+		var res realworld.JWKSet
+		err := requests.URL(address).Path("./.well-known/jwks.json").
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		// NOTE: symmetric secret used by default must never be published
+		be.Equal(t, 0, len(res.Keys))
+	})
+
 	t.Run("POST /api/users", func(t *testing.T) {
 		badcases := []PostUserRequest{
 			{Name: "", Email: "user@email.com", Password: "password"},
@@ -59,9 +73,26 @@ func TestRun(t *testing.T) {
 			be.NilErr(t, err)
 		}
 
+		invalids := map[string]PostUserRequest{
+			"username": {Name: "user name", Email: "user@email.com", Password: "password"},
+			"email":    {Name: "username", Email: "user@", Password: "password"},
+			"password": {Name: "username", Email: "user@email.com", Password: "short"},
+		}
+		for field, tc := range invalids {
+			var res ErrorResponseBody
+			err := requests.URL(address).Path("./api/users").
+				BodyJSON(&PostUserRequestBody{User: tc}).
+				ToJSON(&res).
+				CheckStatus(422).
+				Fetch(ctx)
+			be.NilErr(t, err)
+			be.Equal(t, 1, len(res.Errors))
+			be.Equal(t, 1, len(res.Errors[field]))
+		}
+
 		req := PostUserRequestBody{User: PostUserRequest{
-			Name:     testUser.Name,
-			Email:    testUser.Email,
+			Name:     "registereduser",
+			Email:    "registereduser@email.com",
 			Password: password,
 		}}
 		var res PostUserResponseBody
@@ -74,6 +105,21 @@ func TestRun(t *testing.T) {
 
 		be.Equal(t, req.User.Name, res.User.Name)
 		be.Equal(t, req.User.Email, res.User.Email)
+
+		conflicts := map[string]PostUserRequest{
+			"email":    {Name: "otheruser", Email: testUser.Email, Password: password},
+			"username": {Name: testUser.Name, Email: "otheruser@email.com", Password: password},
+		}
+		for field, tc := range conflicts {
+			var res ErrorResponseBody
+			err := requests.URL(address).Path("./api/users").
+				BodyJSON(&PostUserRequestBody{User: tc}).
+				ToJSON(&res).
+				CheckStatus(422).
+				Fetch(ctx)
+			be.NilErr(t, err)
+			be.AllEqual(t, []string{"has already been taken"}, res.Errors[field])
+		}
 	})
 
 	t.Run("POST /api/users/login", func(t *testing.T) {
@@ -105,6 +151,105 @@ func TestRun(t *testing.T) {
 		be.Nonzero(t, res.User.Token)
 	})
 
+	t.Run("POST /api/users/token/refresh", func(t *testing.T) {
+		refresh := func(token string) *requests.Builder {
+			return requests.URL(address).Path("./api/users/token/refresh").
+				BodyJSON(&RefreshTokenRequestBody{User: RefreshTokenRequest{RefreshToken: token}})
+		}
+		err := refresh("").CheckStatus(422).Fetch(ctx)
+		be.NilErr(t, err)
+		err = refresh("unknown-token").CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+
+		var login PostUserResponseBody
+		err = requests.URL(address).Path("./api/users/login").
+			BodyJSON(&PostUserLoginRequestBody{User: PostUserLoginRequest{Email: testUser.Email, Password: password}}).
+			CheckStatus(200).ToJSON(&login).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Nonzero(t, login.User.RefreshToken)
+
+		var res PostUserResponseBody
+		headers := http.Header{}
+		err = refresh(login.User.RefreshToken).CheckStatus(200).ToJSON(&res).CopyHeaders(headers).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, testUser.Email, res.User.Email)
+		be.Unequal(t, login.User.RefreshToken, res.User.RefreshToken)
+
+		// NOTE: session cookie is renewed as well, so that cookie session outlives access token
+		cookies := map[string]*http.Cookie{}
+		for _, cookie := range (&http.Response{Header: headers}).Cookies() {
+			cookies[cookie.Name] = cookie
+		}
+		be.True(t, cookies["session"] != nil && cookies["csrf_token"] != nil)
+		be.Equal(t, res.User.Token, cookies["session"].Value)
+
+		err = requests.URL(address).Path("./api/user").
+			Header("Authorization", "Token "+res.User.Token).
+			CheckStatus(200).Fetch(ctx)
+		be.NilErr(t, err)
+
+		// NOTE: reusing rotated refresh token revokes the refresh token rotated from it as well
+		err = refresh(login.User.RefreshToken).CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+		err = refresh(res.User.RefreshToken).CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+	})
+
+	t.Run("POST /api/users/logout", func(t *testing.T) {
+		err := requests.URL(address).Path("./api/users/logout").Post().
+			CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+
+		user := postUser(t, ctx, address, "logoutuser")
+		login := func() PostUserResponse {
+			var res PostUserResponseBody
+			err := requests.URL(address).Path("./api/users/login").
+				BodyJSON(&PostUserLoginRequestBody{User: PostUserLoginRequest{Email: user.Email, Password: user.Name + "-password"}}).
+				CheckStatus(200).ToJSON(&res).Fetch(ctx)
+			be.NilErr(t, err)
+			return res.User
+		}
+		getUser := func(token string, status int) {
+			err := requests.URL(address).Path("./api/user").
+				Header("Authorization", "Token "+token).
+				CheckStatus(status).Fetch(ctx)
+			be.NilErr(t, err)
+		}
+		refresh := func(token string, status int) {
+			err := requests.URL(address).Path("./api/users/token/refresh").
+				BodyJSON(&RefreshTokenRequestBody{User: RefreshTokenRequest{RefreshToken: token}}).
+				CheckStatus(status).Fetch(ctx)
+			be.NilErr(t, err)
+		}
+
+		first, second := login(), login()
+		// NOTE: updating user must not start a session that survives logging out of the current one
+		var updated PostUserResponseBody
+		err = requests.URL(address).Path("./api/user").Put().
+			Header("Authorization", "Token "+first.Token).
+			BodyJSON(&PutUserRequestBody{}).
+			CheckStatus(200).ToJSON(&updated).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, first.Token, updated.User.Token)
+		be.Zero(t, updated.User.RefreshToken)
+		err = requests.URL(address).Path("./api/users/logout").Post().
+			Header("Authorization", "Token "+first.Token).
+			CheckStatus(204).Fetch(ctx)
+		be.NilErr(t, err)
+		getUser(first.Token, 401)
+		refresh(first.RefreshToken, 401)
+		getUser(second.Token, 200)
+
+		err = requests.URL(address).Path("./api/users/logout").Post().Param("all", "true").
+			Header("Authorization", "Token "+second.Token).
+			CheckStatus(204).Fetch(ctx)
+		be.NilErr(t, err)
+		getUser(second.Token, 401)
+		getUser(user.Token, 401)
+		refresh(second.RefreshToken, 401)
+		getUser(login().Token, 200)
+	})
+
 	t.Run("GET /api/user", func(t *testing.T) {
 		err := requests.URL(address).Path("./api/user").CheckStatus(401).Fetch(ctx)
 		be.NilErr(t, err)
@@ -123,6 +268,150 @@ func TestRun(t *testing.T) {
 		be.Equal(t, testUser.Token, res.User.Token)
 	})
 
+	t.Run("Authorization", func(t *testing.T) {
+		for _, header := range []string{"Token ", "Bearer ", "bearer ", "TOKEN "} {
+			err := requests.URL(address).Path("./api/user").
+				Header("Authorization", header+testUser.Token).
+				CheckStatus(200).Fetch(ctx)
+			be.NilErr(t, err)
+		}
+		for _, header := range []string{"Basic " + testUser.Token, testUser.Token, "Bearer", "Bearer  " + testUser.Token} {
+			err := requests.URL(address).Path("./api/user").
+				Header("Authorization", header).
+				CheckStatus(401).Fetch(ctx)
+			be.NilErr(t, err)
+		}
+
+		headers := http.Header{}
+		err := requests.URL(address).Path("./api/users/login").
+			BodyJSON(&PostUserLoginRequestBody{User: PostUserLoginRequest{Email: testUser.Email, Password: password}}).
+			CopyHeaders(headers).CheckStatus(200).Fetch(ctx)
+		be.NilErr(t, err)
+		cookies := map[string]*http.Cookie{}
+		for _, cookie := range (&http.Response{Header: headers}).Cookies() {
+			cookies[cookie.Name] = cookie
+		}
+		session, csrf := cookies["session"], cookies["csrf_token"]
+		be.True(t, session != nil && csrf != nil)
+		be.True(t, session.HttpOnly)
+		be.False(t, csrf.HttpOnly)
+
+		err = requests.URL(address).Path("./api/user").
+			Cookie(session.Name, session.Value).
+			CheckStatus(200).Fetch(ctx)
+		be.NilErr(t, err)
+
+		put := func() *requests.Builder {
+			return requests.URL(address).Path("./api/user").Put().
+				BodyJSON(&PutUserRequestBody{}).
+				Cookie(session.Name, session.Value).Cookie(csrf.Name, csrf.Value)
+		}
+		err = put().CheckStatus(403).Fetch(ctx)
+		be.NilErr(t, err)
+		err = put().Header("X-CSRF-Token", "forged").CheckStatus(403).Fetch(ctx)
+		be.NilErr(t, err)
+		err = put().Header("X-CSRF-Token", csrf.Value).CheckStatus(200).Fetch(ctx)
+		be.NilErr(t, err)
+	})
+
+	t.Run("PUT /api/user", func(t *testing.T) {
+		bio := "updated bio"
+		err := requests.URL(address).Path("./api/user").Put().
+			BodyJSON(&PutUserRequestBody{User: PutUserRequest{Bio: &bio}}).
+			CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+
+		empty := ""
+		err = requests.URL(address).Path("./api/user").Put().
+			Header("Authorization", "Token "+testUser.Token).
+			BodyJSON(&PutUserRequestBody{User: PutUserRequest{Email: &empty}}).
+			CheckStatus(422).Fetch(ctx)
+		be.NilErr(t, err)
+
+		var res PostUserResponseBody
+		err = requests.URL(address).Path("./api/user").Put().
+			Header("Authorization", "Token "+testUser.Token).
+			BodyJSON(&PutUserRequestBody{User: PutUserRequest{Bio: &bio}}).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, bio, res.User.Bio)
+		be.Equal(t, testUser.Email, res.User.Email)
+
+		image := "javascript:alert(1)"
+		var errRes ErrorResponseBody
+		err = requests.URL(address).Path("./api/user").Put().
+			Header("Authorization", "Token "+testUser.Token).
+			BodyJSON(&PutUserRequestBody{User: PutUserRequest{Image: &image}}).
+			CheckStatus(422).ToJSON(&errRes).Fetch(ctx)
+		be.NilErr(t, err)
+		be.AllEqual(t, []string{"is invalid"}, errRes.Errors["image"])
+
+		other := PostUserRequestBody{User: PostUserRequest{
+			Name:     "otheruser",
+			Email:    "otheruser@email.com",
+			Password: "otheruser-password",
+		}}
+		err = requests.URL(address).Path("./api/users").
+			BodyJSON(&other).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path("./api/user").Put().
+			Header("Authorization", "Token "+res.User.Token).
+			BodyJSON(&PutUserRequestBody{User: PutUserRequest{Email: &testUser.Email}}).
+			CheckStatus(422).Fetch(ctx)
+		be.NilErr(t, err)
+
+		email := "changed@email.com"
+		err = requests.URL(address).Path("./api/user").Put().
+			Header("Authorization", "Token "+res.User.Token).
+			BodyJSON(&PutUserRequestBody{User: PutUserRequest{Email: &email}}).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, email, res.User.Email)
+
+		err = requests.URL(address).Path("./api/user").
+			Header("Authorization", "Token "+res.User.Token).
+			CheckStatus(200).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path("./api/users/login").
+			BodyJSON(&PostUserLoginRequestBody{User: PostUserLoginRequest{Email: email, Password: other.User.Password}}).
+			CheckStatus(200).Fetch(ctx)
+		be.NilErr(t, err)
+	})
+
+	t.Run("problem details", func(t *testing.T) {
+		var problem ProblemDetails
+		err := requests.URL(address).Path("./api/profiles/unknown-user").
+			Header("Accept", "application/problem+json").
+			CheckStatus(404).CheckContentType("application/problem+json").
+			ToJSON(&problem).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, "urn:realworld:problem:user-not-found", problem.Type)
+		be.Equal(t, "User not found", problem.Title)
+		be.Equal(t, 404, problem.Status)
+		be.Equal(t, "/api/profiles/unknown-user", problem.Instance)
+		be.Nonzero(t, problem.Detail)
+
+		problem = ProblemDetails{}
+		err = requests.URL(address).Path("./api/users").
+			Header("Accept", "application/json;q=0.5, application/problem+json").
+			BodyJSON(&PostUserRequestBody{User: PostUserRequest{Name: "", Email: "user@", Password: "password"}}).
+			CheckStatus(422).CheckContentType("application/problem+json").
+			ToJSON(&problem).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, "urn:realworld:problem:bad-request", problem.Type)
+		be.AllEqual(t, []InvalidParam{{Name: "username", Reason: "can't be blank"}, {Name: "email", Reason: "is invalid"}}, problem.InvalidParams)
+
+		var legacy ErrorResponseBody
+		err = requests.URL(address).Path("./api/profiles/unknown-user").
+			Header("Accept", "*/*").
+			CheckStatus(404).CheckContentType("application/json").
+			ToJSON(&legacy).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Nonzero(t, legacy.Errors["body"])
+	})
+
 	t.Run("GET /api/profiles/{username}", func(t *testing.T) {
 		var errRes ErrorResponseBody
 		err := requests.URL(address).Path("./api/profiles/unknown-user").
@@ -135,8 +424,405 @@ func TestRun(t *testing.T) {
 			CheckStatus(200).ToJSON(&res).Fetch(ctx)
 		be.NilErr(t, err)
 		be.Equal(t, testUser.Name, res.Profile.Username)
+		be.False(t, res.Profile.Following)
+	})
+
+	t.Run("POST /api/profiles/{username}/follow", func(t *testing.T) {
+		err := requests.URL(address).Path("./api/profiles/otheruser/follow").Post().
+			CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path("./api/profiles/unknown-user/follow").Post().
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(404).Fetch(ctx)
+		be.NilErr(t, err)
+
+		var res GetProfilesResponseBody
+		err = requests.URL(address).Path("./api/profiles/otheruser/follow").Post().
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, "otheruser", res.Profile.Username)
+		be.True(t, res.Profile.Following)
+
+		err = requests.URL(address).Path("./api/profiles/otheruser").
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.True(t, res.Profile.Following)
+
+		err = requests.URL(address).Path("./api/profiles/otheruser").
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.False(t, res.Profile.Following)
+	})
+
+	t.Run("DELETE /api/profiles/{username}/follow", func(t *testing.T) {
+		var res GetProfilesResponseBody
+		err := requests.URL(address).Path("./api/profiles/otheruser/follow").Delete().
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.False(t, res.Profile.Following)
+
+		err = requests.URL(address).Path("./api/profiles/otheruser").
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.False(t, res.Profile.Following)
+	})
+
+	var article ArticleResponse
+	t.Run("POST /api/articles", func(t *testing.T) {
+		req := PostArticleRequestBody{Article: PostArticleRequest{
+			Title:       "How to train your dragon",
+			Description: "Ever wonder how?",
+			Body:        "You have to believe",
+			TagList:     []string{"dragons", "training"},
+		}}
+		err := requests.URL(address).Path("./api/articles").
+			BodyJSON(&req).CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path("./api/articles").
+			Header("Authorization", "Token "+testUser.Token).
+			BodyJSON(&PostArticleRequestBody{Article: PostArticleRequest{Title: "no body"}}).
+			CheckStatus(422).Fetch(ctx)
+		be.NilErr(t, err)
+
+		var res ArticleResponseBody
+		err = requests.URL(address).Path("./api/articles").
+			Header("Authorization", "Token "+testUser.Token).
+			BodyJSON(&req).CheckStatus(201).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, "how-to-train-your-dragon", res.Article.Slug)
+		be.Equal(t, req.Article.Body, res.Article.Body)
+		be.AllEqual(t, req.Article.TagList, res.Article.TagList)
+		be.Equal(t, testUser.Name, res.Article.Author.Username)
+		be.Nonzero(t, res.Article.CreatedAt)
+
+		var duplicated ArticleResponseBody
+		err = requests.URL(address).Path("./api/articles").
+			Header("Authorization", "Token "+testUser.Token).
+			BodyJSON(&req).CheckStatus(201).ToJSON(&duplicated).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Unequal(t, res.Article.Slug, duplicated.Article.Slug)
+		article = res.Article
+	})
+
+	t.Run("GET /api/articles/{slug}", func(t *testing.T) {
+		err := requests.URL(address).Path("./api/articles/unknown-slug").
+			CheckStatus(404).Fetch(ctx)
+		be.NilErr(t, err)
+
+		var res ArticleResponseBody
+		err = requests.URL(address).Path("./api/articles/" + article.Slug).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, article.Title, res.Article.Title)
+		be.Equal(t, testUser.Name, res.Article.Author.Username)
+	})
+
+	t.Run("PUT /api/articles/{slug}", func(t *testing.T) {
+		title := "How to train your dragon again"
+		req := PutArticleRequestBody{Article: PutArticleRequest{Title: &title}}
+		other := postUser(t, ctx, address, "articleuser")
+		err := requests.URL(address).Path("./api/articles/"+article.Slug).Put().
+			Header("Authorization", "Token "+other.Token).
+			BodyJSON(&req).CheckStatus(403).Fetch(ctx)
+		be.NilErr(t, err)
+
+		var res ArticleResponseBody
+		err = requests.URL(address).Path("./api/articles/"+article.Slug).Put().
+			Header("Authorization", "Token "+testUser.Token).
+			BodyJSON(&req).CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, "how-to-train-your-dragon-again", res.Article.Slug)
+		be.Equal(t, article.Body, res.Article.Body)
+
+		err = requests.URL(address).Path("./api/articles/" + article.Slug).
+			CheckStatus(404).Fetch(ctx)
+		be.NilErr(t, err)
+		article = res.Article
+	})
+
+	t.Run("DELETE /api/articles/{slug}", func(t *testing.T) {
+		other := postUser(t, ctx, address, "deleteuser")
+		err := requests.URL(address).Path("./api/articles/"+article.Slug).Delete().
+			Header("Authorization", "Token "+other.Token).
+			CheckStatus(403).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path("./api/articles/"+article.Slug).Delete().
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(204).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path("./api/articles/" + article.Slug).
+			CheckStatus(404).Fetch(ctx)
+		be.NilErr(t, err)
+	})
+
+	t.Run("GET /api/articles", func(t *testing.T) {
+		author := postUser(t, ctx, address, "listuser")
+		titles := []string{"first listed", "second listed", "third listed"}
+		for _, title := range titles {
+			postArticle(t, ctx, address, author.Token, title, "listed")
+		}
+		postArticle(t, ctx, address, testUser.Token, "not listed by author", "listed")
+
+		var res ArticlesResponseBody
+		err := requests.URL(address).Path("./api/articles").
+			Param("author", author.Name).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, 3, res.ArticlesCount)
+		be.Equal(t, 3, len(res.Articles))
+		be.Equal(t, "third listed", res.Articles[0].Title)
+
+		err = requests.URL(address).Path("./api/articles").
+			Param("tag", "listed").Param("limit", "2").Param("offset", "1").
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, 4, res.ArticlesCount)
+		be.Equal(t, 2, len(res.Articles))
+		be.Equal(t, "third listed", res.Articles[0].Title)
+		be.Equal(t, "second listed", res.Articles[1].Title)
+
+		err = requests.URL(address).Path("./api/articles").
+			Param("author", "unknown-user").
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, 0, res.ArticlesCount)
+		be.Equal(t, 0, len(res.Articles))
+
+		err = requests.URL(address).Path("./api/articles").
+			Param("limit", "-1").CheckStatus(422).Fetch(ctx)
+		be.NilErr(t, err)
+	})
+
+	t.Run("GET /api/articles/feed", func(t *testing.T) {
+		err := requests.URL(address).Path("./api/articles/feed").
+			CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+
+		reader := postUser(t, ctx, address, "feeduser")
+		var res ArticlesResponseBody
+		err = requests.URL(address).Path("./api/articles/feed").
+			Header("Authorization", "Token "+reader.Token).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, 0, res.ArticlesCount)
+		be.Equal(t, 0, len(res.Articles))
+
+		followed := postUser(t, ctx, address, "feedauthor")
+		postArticle(t, ctx, address, followed.Token, "first feed")
+		postArticle(t, ctx, address, followed.Token, "second feed")
+		for _, username := range []string{"listuser", followed.Name} {
+			err = requests.URL(address).Pathf("./api/profiles/%s/follow", username).Post().
+				Header("Authorization", "Token "+reader.Token).
+				CheckStatus(200).Fetch(ctx)
+			be.NilErr(t, err)
+		}
+
+		err = requests.URL(address).Path("./api/articles/feed").
+			Header("Authorization", "Token "+reader.Token).
+			Param("limit", "3").Param("offset", "1").
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, 5, res.ArticlesCount)
+		be.Equal(t, 3, len(res.Articles))
+		be.Equal(t, "first feed", res.Articles[0].Title)
+		be.Equal(t, "third listed", res.Articles[1].Title)
+		be.Equal(t, "second listed", res.Articles[2].Title)
+		be.True(t, res.Articles[0].Author.Following)
+	})
+
+	t.Run("/api/articles/{slug}/favorite", func(t *testing.T) {
+		favorited := postArticle(t, ctx, address, testUser.Token, "favorited article")
+		fan := postUser(t, ctx, address, "favoriteuser")
+		path := "./api/articles/" + favorited.Slug + "/favorite"
+		be.False(t, favorited.Favorited)
+		be.Equal(t, 0, favorited.FavoritesCount)
+
+		err := requests.URL(address).Path(path).Post().
+			CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path("./api/articles/unknown-slug/favorite").Post().
+			Header("Authorization", "Token "+fan.Token).
+			CheckStatus(404).Fetch(ctx)
+		be.NilErr(t, err)
+
+		var res ArticleResponseBody
+		for range 2 {
+			err = requests.URL(address).Path(path).Post().
+				Header("Authorization", "Token "+fan.Token).
+				CheckStatus(200).ToJSON(&res).Fetch(ctx)
+			be.NilErr(t, err)
+			be.True(t, res.Article.Favorited)
+			be.Equal(t, 1, res.Article.FavoritesCount)
+		}
+
+		err = requests.URL(address).Path("./api/articles/"+favorited.Slug).
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.False(t, res.Article.Favorited)
+		be.Equal(t, 1, res.Article.FavoritesCount)
+
+		var list ArticlesResponseBody
+		err = requests.URL(address).Path("./api/articles").
+			Param("favorited", fan.Name).
+			Header("Authorization", "Token "+fan.Token).
+			CheckStatus(200).ToJSON(&list).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, 1, list.ArticlesCount)
+		be.Equal(t, favorited.Slug, list.Articles[0].Slug)
+		be.True(t, list.Articles[0].Favorited)
+
+		err = requests.URL(address).Path(path).Delete().
+			Header("Authorization", "Token "+fan.Token).
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.False(t, res.Article.Favorited)
+		be.Equal(t, 0, res.Article.FavoritesCount)
+	})
+
+	t.Run("GET /api/tags", func(t *testing.T) {
+		tagged := postArticle(t, ctx, address, testUser.Token, "tagged article", " Golang", "golang", "HTTP ", "")
+		be.AllEqual(t, []string{"golang", "http"}, tagged.TagList)
+
+		var res TagsResponseBody
+		err := requests.URL(address).Path("./api/tags").
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.True(t, slices.Contains(res.Tags, "golang"))
+		be.True(t, slices.Contains(res.Tags, "http"))
+		be.True(t, slices.IsSorted(res.Tags))
+
+		var list ArticlesResponseBody
+		err = requests.URL(address).Path("./api/articles").
+			Param("tag", "GoLang").
+			CheckStatus(200).ToJSON(&list).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, 1, list.ArticlesCount)
+		be.Equal(t, tagged.Slug, list.Articles[0].Slug)
+
+		err = requests.URL(address).Path("./api/articles/"+tagged.Slug).Delete().
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(204).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path("./api/tags").
+			CheckStatus(200).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.False(t, slices.Contains(res.Tags, "golang"))
+	})
+
+	t.Run("/api/articles/{slug}/comments", func(t *testing.T) {
+		commented := postArticle(t, ctx, address, testUser.Token, "commented article")
+		commenter := postUser(t, ctx, address, "commentuser")
+		path := "./api/articles/" + commented.Slug + "/comments"
+
+		req := PostCommentRequestBody{Comment: PostCommentRequest{Body: "Thank you so much!"}}
+		err := requests.URL(address).Path(path).
+			BodyJSON(&req).CheckStatus(401).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path("./api/articles/unknown-slug/comments").
+			Header("Authorization", "Token "+commenter.Token).
+			BodyJSON(&req).CheckStatus(404).Fetch(ctx)
+		be.NilErr(t, err)
+
+		var res CommentResponseBody
+		err = requests.URL(address).Path(path).
+			Header("Authorization", "Token "+commenter.Token).
+			BodyJSON(&req).CheckStatus(201).ToJSON(&res).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, req.Comment.Body, res.Comment.Body)
+		be.Equal(t, commenter.Name, res.Comment.Author.Username)
+		be.Nonzero(t, res.Comment.ID)
+
+		err = requests.URL(address).Path("./api/profiles/commentuser/follow").Post().
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(200).Fetch(ctx)
+		be.NilErr(t, err)
+
+		var list CommentsResponseBody
+		err = requests.URL(address).Path(path).
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(200).ToJSON(&list).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, 1, len(list.Comments))
+		be.Equal(t, res.Comment.ID, list.Comments[0].ID)
+		be.True(t, list.Comments[0].Author.Following)
+
+		err = requests.URL(address).Pathf("%s/%d", path, res.Comment.ID).Delete().
+			Header("Authorization", "Token "+testUser.Token).
+			CheckStatus(403).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Pathf("%s/%d", path, res.Comment.ID).Delete().
+			Header("Authorization", "Token "+commenter.Token).
+			CheckStatus(204).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Pathf("%s/%d", path, res.Comment.ID).Delete().
+			Header("Authorization", "Token "+commenter.Token).
+			CheckStatus(404).Fetch(ctx)
+		be.NilErr(t, err)
+
+		err = requests.URL(address).Path(path).
+			CheckStatus(200).ToJSON(&list).Fetch(ctx)
+		be.NilErr(t, err)
+		be.Equal(t, 0, len(list.Comments))
 	})
+}
+
+func TestAcceptsProblem(t *testing.T) {
+	testcases := map[string]bool{
+		"":                             false,
+		"*/*":                          false,
+		"application/json":             false,
+		"application/problem+json":     true,
+		"application/problem+json;q=0": false,
+		"application/json, application/problem+json":             true,
+		"application/json, application/problem+json;q=0.9":       false,
+		"application/json;q=0.5, application/problem+json;q=0.9": true,
+	}
+	for accept, want := range testcases {
+		be.Equal(t, want, acceptsProblem(accept))
+	}
+}
 
+func TestRunMigrate(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "realworld.db")
+	migrate := func(command string) string {
+		var out strings.Builder
+		err := runMigrate(ctx, &out, []string{"migrate", "--db", "sqlite", "--db-file", file, command})
+		be.NilErr(t, err)
+		return out.String()
+	}
+
+	be.In(t, "0001_init\tpending", migrate("status"))
+	applied := migrate("up")
+	be.In(t, "applied 0001_init", applied)
+	be.In(t, "applied 0002_case_insensitive_users", applied)
+	be.In(t, "applied 0003_user_ids", applied)
+	be.In(t, "no pending migration", migrate("up"))
+	be.In(t, "0003_user_ids\tapplied", migrate("status"))
+	be.In(t, "reverted 0003_user_ids", migrate("down"))
+	be.In(t, "0003_user_ids\tpending", migrate("status"))
+	be.In(t, "reverted 0002_case_insensitive_users", migrate("down"))
+	be.In(t, "0002_case_insensitive_users\tpending", migrate("status"))
+	be.In(t, "reverted 0001_init", migrate("down"))
+	be.In(t, "0001_init\tpending", migrate("status"))
+
+	var out strings.Builder
+	be.Nonzero(t, runMigrate(ctx, &out, []string{"migrate", "--db", "sqlite", "--db-file", file, "sideways"}))
+	be.Nonzero(t, runMigrate(ctx, &out, []string{"migrate", "status"}))
 }
 
 func getFreePort(t *testing.T) string {
@@ -189,3 +875,39 @@ func postTestUser(t *testing.T, ctx context.Context, address string) (PostUserRe
 	}
 	return res.User, req.User.Password
 }
+
+func postUser(t *testing.T, ctx context.Context, address, name string) PostUserResponse {
+	req := PostUserRequestBody{
+		User: PostUserRequest{
+			Name:     name,
+			Email:    name + "@email.com",
+			Password: name + "-password",
+		},
+	}
+	var res PostUserResponseBody
+	err := requests.URL(address).Path("./api/users").
+		BodyJSON(&req).ToJSON(&res).
+		Fetch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.User
+}
+
+func postArticle(t *testing.T, ctx context.Context, address, token, title string, tags ...string) ArticleResponse {
+	req := PostArticleRequestBody{Article: PostArticleRequest{
+		Title:       title,
+		Description: title + " description",
+		Body:        title + " body",
+		TagList:     tags,
+	}}
+	var res ArticleResponseBody
+	err := requests.URL(address).Path("./api/articles").
+		Header("Authorization", "Token "+token).
+		BodyJSON(&req).ToJSON(&res).
+		Fetch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.Article
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// runMigrate runs migrate subcommand, where args[0] is migrate
+func runMigrate(ctx context.Context, w io.Writer, args []string) error {
+	var d database
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(w)
+	d.addFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(w, "Usage of %s:\n", fs.Name())
+		fmt.Fprintf(w, "  migrate [flags] up|down|status\n\n")
+		fmt.Fprintf(w, "  up      apply every pending migration\n")
+		fmt.Fprintf(w, "  down    revert the latest applied migration\n")
+		fmt.Fprintf(w, "  status  list migrations with whether applied\n\n")
+		fmt.Fprintf(w, "Flags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("migrate: expected exactly one of up, down or status")
+	}
+
+	migrator, closeDB, err := d.migrator(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	switch fs.Arg(0) {
+	case "up":
+		migrated, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if len(migrated) == 0 {
+			fmt.Fprintln(w, "no pending migration")
+		}
+		for _, migration := range migrated {
+			fmt.Fprintf(w, "applied %04d_%s\n", migration.Version, migration.Name)
+		}
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "reverted %04d_%s\n", migration.Version, migration.Name)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(w, "%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		fs.Usage()
+		return fmt.Errorf("migrate: unknown command %s", fs.Arg(0))
+	}
+	return nil
+}
@@ -1,9 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/carlmjohnson/versioninfo"
@@ -11,13 +12,33 @@ import (
 )
 
 // TODO: refactor this function into new file route.go
-func newServer(userService realworld.UserService, authService realworld.UserAuthService) http.Handler {
+// newServer sets session cookie on login and refresh if sessionCookie is set, while session cookie is accepted regardless
+func newServer(userService realworld.UserService, authService realworld.UserAuthService, articleService realworld.ArticleService, commentService realworld.CommentService, tagService realworld.TagService, sessionCookie bool) http.Handler {
+	requireAuth, optionalAuth := RequireAuth(authService), OptionalAuth(authService)
 	mux := http.NewServeMux()
 	mux.Handle("GET /health", handleHealthCheck())
+	mux.Handle("GET /.well-known/jwks.json", handleGetJWKS(authService))
 	mux.Handle("POST /api/users", handlePostUsers(userService, authService))
-	mux.Handle("POST /api/users/login", handlePostUsersLogin(authService))
-	mux.Handle("GET /api/user", handleGetUser(authService))
-	mux.Handle("GET /api/profiles/{username}", handleGetProfile(userService))
+	mux.Handle("POST /api/users/login", handlePostUsersLogin(authService, sessionCookie))
+	mux.Handle("POST /api/users/token/refresh", handlePostUsersTokenRefresh(authService, sessionCookie))
+	mux.Handle("POST /api/users/logout", requireAuth(handlePostUsersLogout(authService)))
+	mux.Handle("GET /api/user", requireAuth(handleGetUser()))
+	mux.Handle("PUT /api/user", requireAuth(handlePutUser(userService)))
+	mux.Handle("GET /api/profiles/{username}", optionalAuth(handleGetProfile(userService)))
+	mux.Handle("POST /api/profiles/{username}/follow", requireAuth(handlePostProfileFollow(userService)))
+	mux.Handle("DELETE /api/profiles/{username}/follow", requireAuth(handleDeleteProfileFollow(userService)))
+	mux.Handle("GET /api/articles", optionalAuth(handleGetArticles(articleService)))
+	mux.Handle("GET /api/articles/feed", requireAuth(handleGetArticlesFeed(articleService)))
+	mux.Handle("POST /api/articles", requireAuth(handlePostArticles(articleService)))
+	mux.Handle("GET /api/articles/{slug}", optionalAuth(handleGetArticle(articleService)))
+	mux.Handle("PUT /api/articles/{slug}", requireAuth(handlePutArticle(articleService)))
+	mux.Handle("DELETE /api/articles/{slug}", requireAuth(handleDeleteArticle(articleService)))
+	mux.Handle("POST /api/articles/{slug}/favorite", requireAuth(handlePostArticleFavorite(articleService)))
+	mux.Handle("DELETE /api/articles/{slug}/favorite", requireAuth(handleDeleteArticleFavorite(articleService)))
+	mux.Handle("POST /api/articles/{slug}/comments", requireAuth(handlePostComments(commentService)))
+	mux.Handle("GET /api/articles/{slug}/comments", optionalAuth(handleGetComments(commentService)))
+	mux.Handle("DELETE /api/articles/{slug}/comments/{id}", requireAuth(handleDeleteComment(commentService)))
+	mux.Handle("GET /api/tags", handleGetTags(tagService))
 	return loggingMiddleware(mux)
 }
 
@@ -43,88 +64,387 @@ func handleHealthCheck() http.Handler {
 	})
 }
 
+func handleGetJWKS(auth realworld.UserAuthService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = encode(w, 200, auth.JWKS())
+	})
+}
+
 func handlePostUsers(service realworld.UserService, auth realworld.UserAuthService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		req, err := decode[PostUserRequestBody](r)
 		if err != nil {
-			_ = encodeError(w, err)
+			_ = encodeError(w, r, err)
 			return
 		}
 		if err := req.Valid(); err != nil {
-			_ = encodeError(w, err)
+			_ = encodeError(w, r, err)
 			return
 		}
 		user, err := service.CreateUser(r.Context(), req.toUser())
 		if err != nil {
-			_ = encodeError(w, err)
+			_ = encodeError(w, r, err)
 			return
 		}
-		authUser, err := auth.Login(r.Context(), user.Email, user.Password)
+		authUser, err := auth.IssueToken(r.Context(), user)
 		if err != nil {
-			_ = encodeError(w, err)
+			_ = encodeError(w, r, err)
 			return
 		}
 		_ = encode(w, 201, newPostUserResponseBody(authUser))
 	})
 }
 
-func handlePostUsersLogin(service realworld.UserAuthService) http.Handler {
+func handlePostUsersLogin(service realworld.UserAuthService, sessionCookie bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		req, err := decode[PostUserLoginRequestBody](r)
 		if err != nil {
-			_ = encodeError(w, err)
+			_ = encodeError(w, r, err)
 			return
 		}
 		if err := req.Valid(); err != nil {
-			_ = encodeError(w, err)
+			_ = encodeError(w, r, err)
 			return
 		}
 		user, err := service.Login(r.Context(), req.User.Email, req.User.Password)
 		if err != nil {
-			_ = encodeError(w, err)
+			_ = encodeError(w, r, err)
 			return
 		}
+		if sessionCookie {
+			if err := setSessionCookies(w, user.Token); err != nil {
+				_ = encodeError(w, r, err)
+				return
+			}
+		}
 		_ = encode(w, 200, newPostUserResponseBody(user))
 		// TODO: validate req
 	})
 }
 
-func handleGetUser(auth realworld.UserAuthService) http.Handler {
+func handlePostUsersTokenRefresh(service realworld.UserAuthService, sessionCookie bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Token ")
-		user, err := auth.Authenticate(r.Context(), token)
+		req, err := decode[RefreshTokenRequestBody](r)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		if err := req.Valid(); err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		user, err := service.Refresh(r.Context(), req.User.RefreshToken)
 		if err != nil {
-			_ = encodeError(w, err)
+			_ = encodeError(w, r, err)
 			return
 		}
+		if sessionCookie {
+			if err := setSessionCookies(w, user.Token); err != nil {
+				_ = encodeError(w, r, err)
+				return
+			}
+		}
 		_ = encode(w, 200, newPostUserResponseBody(user))
 	})
 }
 
+// handlePostUsersLogout logs out of every session with query parameter all=true
+func handlePostUsersLogout(service realworld.UserAuthService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		all := false
+		if query := r.URL.Query(); query.Has("all") {
+			var err error
+			if all, err = strconv.ParseBool(query.Get("all")); err != nil {
+				_ = encodeError(w, r, fmt.Errorf("%w: all must be boolean", realworld.ErrBadRequest))
+				return
+			}
+		}
+		user := MustUserFromContext(r.Context())
+		if err := service.Logout(r.Context(), user.Token, all); err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		clearSessionCookies(w)
+		w.WriteHeader(204)
+	})
+}
+
+func handleGetUser() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		_ = encode(w, 200, newPostUserResponseBody(user))
+	})
+}
+
+func handlePutUser(service realworld.UserService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := MustUserFromContext(r.Context())
+		req, err := decode[PutUserRequestBody](r)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		if err := req.Valid(); err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		user, err := service.UpdateUser(r.Context(), current.ID, req.toUserUpdate())
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		// NOTE: token is keyed on user id and survives email change, so the current one is returned.
+		// Issuing new tokens here would start a session that logging out of the current one leaves alive.
+		_ = encode(w, 200, newPostUserResponseBody(realworld.AuthenticatedUser{User: user, Token: current.Token}))
+	})
+}
+
 func handleGetProfile(service realworld.UserService) http.Handler {
-	type profile struct {
-		Username  string `json:"username"`
-		Bio       string `json:"bio"`
-		Image     string `json:"image"`
-		Following bool   `json:"following"`
-	}
-	type profileResponse struct {
-		Profile profile `json:"profile"`
-	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		username := r.PathValue("username")
-		found, err := service.FindProfileByUsername(r.Context(), username)
+		viewer := ViewerFromContext(r.Context())
+		found, err := service.FindProfileByUsername(r.Context(), viewer, r.PathValue("username"))
 		if err != nil {
-			_ = encodeError(w, err)
+			_ = encodeError(w, r, err)
 			return
 		}
-		_ = encode(w, 200, profileResponse{
-			Profile: profile{
-				Username:  found.Username,
-				Bio:       found.Bio,
-				Image:     found.Image,
-				Following: false,
-			},
-		})
+		_ = encode(w, 200, GetProfilesResponseBody{Profile: newProfileResponse(found)})
+	})
+}
+
+func handlePostProfileFollow(service realworld.UserService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		found, err := service.FollowUser(r.Context(), user.ID, r.PathValue("username"))
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, GetProfilesResponseBody{Profile: newProfileResponse(found)})
+	})
+}
+
+func handleDeleteProfileFollow(service realworld.UserService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		found, err := service.UnfollowUser(r.Context(), user.ID, r.PathValue("username"))
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, GetProfilesResponseBody{Profile: newProfileResponse(found)})
+	})
+}
+
+func handleGetArticles(service realworld.ArticleService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viewer := ViewerFromContext(r.Context())
+		page, err := pageFromRequest(r)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		query := r.URL.Query()
+		filter := realworld.ArticleFilter{
+			Tag:       query.Get("tag"),
+			Author:    query.Get("author"),
+			Favorited: query.Get("favorited"),
+		}
+		articles, count, err := service.ListArticles(r.Context(), viewer, filter, page)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, newArticlesResponseBody(articles, count))
+	})
+}
+
+func handleGetArticlesFeed(service realworld.ArticleService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		page, err := pageFromRequest(r)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		articles, count, err := service.Feed(r.Context(), user.ID, page)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, newArticlesResponseBody(articles, count))
+	})
+}
+
+func handlePostArticles(service realworld.ArticleService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		req, err := decode[PostArticleRequestBody](r)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		if err := req.Valid(); err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		article, err := service.CreateArticle(r.Context(), user.ID, req.toArticle())
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 201, ArticleResponseBody{Article: newArticleResponse(article)})
+	})
+}
+
+func handleGetArticle(service realworld.ArticleService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viewer := ViewerFromContext(r.Context())
+		article, err := service.FindArticleBySlug(r.Context(), viewer, r.PathValue("slug"))
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, ArticleResponseBody{Article: newArticleResponse(article)})
+	})
+}
+
+func handlePutArticle(service realworld.ArticleService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		req, err := decode[PutArticleRequestBody](r)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		if err := req.Valid(); err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		article, err := service.UpdateArticle(r.Context(), user.ID, r.PathValue("slug"), req.toArticleUpdate())
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, ArticleResponseBody{Article: newArticleResponse(article)})
+	})
+}
+
+func handleDeleteArticle(service realworld.ArticleService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		if err := service.DeleteArticle(r.Context(), user.ID, r.PathValue("slug")); err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		w.WriteHeader(204)
+	})
+}
+
+func handlePostArticleFavorite(service realworld.ArticleService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		article, err := service.FavoriteArticle(r.Context(), user.ID, r.PathValue("slug"))
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, ArticleResponseBody{Article: newArticleResponse(article)})
+	})
+}
+
+func handleDeleteArticleFavorite(service realworld.ArticleService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		article, err := service.UnfavoriteArticle(r.Context(), user.ID, r.PathValue("slug"))
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, ArticleResponseBody{Article: newArticleResponse(article)})
+	})
+}
+
+func handlePostComments(service realworld.CommentService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		req, err := decode[PostCommentRequestBody](r)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		if err := req.Valid(); err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		comment, err := service.AddComment(r.Context(), user.ID, r.PathValue("slug"), req.Comment.Body)
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 201, CommentResponseBody{Comment: newCommentResponse(comment)})
 	})
 }
+
+func handleGetComments(service realworld.CommentService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viewer := ViewerFromContext(r.Context())
+		comments, err := service.ListComments(r.Context(), viewer, r.PathValue("slug"))
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, newCommentsResponseBody(comments))
+	})
+}
+
+func handleDeleteComment(service realworld.CommentService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := MustUserFromContext(r.Context())
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			_ = encodeError(w, r, fmt.Errorf("%w: comment id must be integer", realworld.ErrBadRequest))
+			return
+		}
+		if err := service.DeleteComment(r.Context(), user.ID, r.PathValue("slug"), id); err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		w.WriteHeader(204)
+	})
+}
+
+func handleGetTags(service realworld.TagService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tags, err := service.ListTags(r.Context())
+		if err != nil {
+			_ = encodeError(w, r, err)
+			return
+		}
+		_ = encode(w, 200, TagsResponseBody{Tags: tags})
+	})
+}
+
+func pageFromRequest(r *http.Request) (realworld.Page, error) {
+	limit, err := nonNegativeQueryParam(r, "limit")
+	if err != nil {
+		return realworld.Page{}, err
+	}
+	offset, err := nonNegativeQueryParam(r, "offset")
+	if err != nil {
+		return realworld.Page{}, err
+	}
+	return realworld.Page{Limit: limit, Offset: offset}, nil
+}
+
+// nonNegativeQueryParam returns zero if query parameter is absent
+func nonNegativeQueryParam(r *http.Request, name string) (int, error) {
+	query := r.URL.Query()
+	if !query.Has(name) {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(query.Get(name))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s must be non-negative integer", realworld.ErrBadRequest, name)
+	}
+	return n, nil
+}
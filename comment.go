@@ -0,0 +1,103 @@
+package realworld
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type Comment struct {
+	// ID is assigned by [CommentRepository]
+	ID        int64
+	ArticleID int64
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// AuthorID references the author stored in [UserRepository]
+	AuthorID string
+	// Author is resolved from AuthorID for the viewer and never persisted
+	Author Profile
+}
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment Comment) (Comment, error)
+	FindCommentByID(ctx context.Context, id int64) (Comment, error)
+	// ListComments returns comments on article oldest first
+	ListComments(ctx context.Context, articleID int64) ([]Comment, error)
+	DeleteComment(ctx context.Context, id int64) error
+}
+
+type CommentService struct {
+	repo     CommentRepository
+	articles ArticleRepository
+	users    UserRepository
+	follows  FollowRepository
+}
+
+func NewCommentService(repo CommentRepository, articles ArticleRepository, users UserRepository, follows FollowRepository) CommentService {
+	return CommentService{repo: repo, articles: articles, users: users, follows: follows}
+}
+
+func (c CommentService) AddComment(ctx context.Context, author, slug, body string) (Comment, error) {
+	article, err := c.articles.FindArticleBySlug(ctx, slug)
+	if err != nil {
+		return Comment{}, err
+	}
+	now := time.Now()
+	comment, err := c.repo.CreateComment(ctx, Comment{
+		ArticleID: article.ID,
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+		AuthorID:  author,
+	})
+	if err != nil {
+		return Comment{}, err
+	}
+	return c.withAuthor(ctx, author, comment)
+}
+
+// ListComments returns comments on article as seen by viewer ID, which is empty for anonymous viewer.
+func (c CommentService) ListComments(ctx context.Context, viewer, slug string) ([]Comment, error) {
+	article, err := c.articles.FindArticleBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := c.repo.ListComments(ctx, article.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i, comment := range comments {
+		if comments[i], err = c.withAuthor(ctx, viewer, comment); err != nil {
+			return nil, err
+		}
+	}
+	return comments, nil
+}
+
+func (c CommentService) DeleteComment(ctx context.Context, author, slug string, id int64) error {
+	article, err := c.articles.FindArticleBySlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+	comment, err := c.repo.FindCommentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if comment.ArticleID != article.ID {
+		return fmt.Errorf("%w with id %d on article %s", ErrCommentNotFound, id, slug)
+	}
+	if comment.AuthorID != author {
+		return fmt.Errorf("%w: comment %d is not written by %s", ErrForbidden, id, author)
+	}
+	return c.repo.DeleteComment(ctx, id)
+}
+
+func (c CommentService) withAuthor(ctx context.Context, viewer string, comment Comment) (Comment, error) {
+	var err error
+	comment.Author, err = profileByID(ctx, c.users, c.follows, viewer, comment.AuthorID)
+	if err != nil {
+		return Comment{}, err
+	}
+	return comment, nil
+}
@@ -10,17 +10,42 @@ type Error string
 func (e Error) Error() string { return string(e) }
 
 const (
-	ErrBadRequest         = Error("bad request")
-	ErrUserNotFound       = Error("user not found")
-	ErrPasswordNotMatched = Error("password not matched")
-	ErrTokenNotFound      = Error("token not found")
-	ErrInvalidToken       = Error("invalid token")
+	ErrBadRequest           = Error("bad request")
+	ErrUserNotFound         = Error("user not found")
+	ErrUserAlreadyExists    = Error("user already exists")
+	ErrPasswordNotMatched   = Error("password not matched")
+	ErrTokenNotFound        = Error("token not found")
+	ErrInvalidToken         = Error("invalid token")
+	ErrTokenExpired         = Error("token expired")
+	ErrTokenRevoked         = Error("token revoked")
+	ErrInvalidRefreshToken  = Error("invalid refresh token")
+	ErrForbidden            = Error("forbidden")
+	ErrArticleNotFound      = Error("article not found")
+	ErrArticleAlreadyExists = Error("article already exists")
+	ErrCommentNotFound      = Error("comment not found")
 )
 
+// FieldError is Err caused by a field of user input, such as email taken by another user
+type FieldError struct {
+	Err     error
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string { return fmt.Sprintf("%s: %s %s", e.Err, e.Field, e.Message) }
+
+func (e FieldError) Unwrap() error { return e.Err }
+
+// ErrorUserAlreadyExists returns [ErrUserAlreadyExists] on field, either email or username, taken by another user
+func ErrorUserAlreadyExists(field string) error {
+	return FieldError{Err: ErrUserAlreadyExists, Field: field, Message: "has already been taken"}
+}
+
+// ErrorIfEmpty returns [FieldError] wrapping [ErrBadRequest] on name if value is zero value
 func ErrorIfEmpty[T comparable](name string, value T) error {
 	var v T
 	if v == value {
-		return fmt.Errorf("%w: %s is required but empty", ErrBadRequest, name)
+		return FieldError{Err: ErrBadRequest, Field: name, Message: "can't be blank"}
 	}
 	return nil
 }
@@ -29,11 +54,13 @@ func StatusFromError(err error) int {
 	switch {
 	case err == nil:
 		return 200
-	case errors.Is(err, ErrTokenNotFound):
+	case errors.Is(err, ErrTokenNotFound) || errors.Is(err, ErrTokenExpired) || errors.Is(err, ErrTokenRevoked) || errors.Is(err, ErrInvalidRefreshToken):
 		return 401
-	case errors.Is(err, ErrUserNotFound):
+	case errors.Is(err, ErrForbidden):
+		return 403
+	case errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrArticleNotFound) || errors.Is(err, ErrCommentNotFound):
 		return 404
-	case errors.Is(err, ErrBadRequest) || errors.Is(err, ErrPasswordNotMatched) || errors.Is(err, ErrInvalidToken):
+	case errors.Is(err, ErrBadRequest) || errors.Is(err, ErrUserAlreadyExists) || errors.Is(err, ErrArticleAlreadyExists) || errors.Is(err, ErrPasswordNotMatched) || errors.Is(err, ErrInvalidToken):
 		return 422
 	default:
 		return 500
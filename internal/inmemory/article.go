@@ -0,0 +1,309 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/raeperd/realworld"
+)
+
+// ArticleRepository implements [realworld.ArticleRepository]
+type ArticleRepository struct {
+	sync.RWMutex
+	memory map[string]*articleRecord
+	// ordered holds slugs of every article ordered by creation time, oldest first
+	ordered []string
+	// byAuthor holds slugs of articles by author id, ordered like ordered
+	byAuthor map[string][]string
+	// byTag holds slugs of articles by tag, ordered like ordered. Tags no longer in use are removed.
+	byTag map[string][]string
+	// byFavoriter holds slugs of articles by id of user favorited them, ordered like ordered
+	byFavoriter map[string][]string
+	lastID      int64
+	// comments of article are deleted with it like foreign key cascading on delete
+	comments *CommentRepository
+}
+
+type articleRecord struct {
+	realworld.Article
+	// favoritedBy is set of user ids
+	favoritedBy map[string]struct{}
+}
+
+// NewArticleRepository returns repository deleting comments of article in comments with the article
+func NewArticleRepository(comments *CommentRepository) *ArticleRepository {
+	return &ArticleRepository{
+		memory:      make(map[string]*articleRecord),
+		byAuthor:    make(map[string][]string),
+		byTag:       make(map[string][]string),
+		byFavoriter: make(map[string][]string),
+		comments:    comments,
+	}
+}
+
+func (ar *ArticleRepository) CreateArticle(ctx context.Context, article realworld.Article) (realworld.Article, error) {
+	ar.Lock()
+	defer ar.Unlock()
+	if _, ok := ar.memory[article.Slug]; ok {
+		return realworld.Article{}, fmt.Errorf("%w with slug %s", realworld.ErrArticleAlreadyExists, article.Slug)
+	}
+	ar.lastID++
+	article.ID = ar.lastID
+	article.TagList = slices.Clone(article.TagList)
+	ar.memory[article.Slug] = &articleRecord{Article: article, favoritedBy: make(map[string]struct{})}
+	ar.ordered = ar.insertOrdered(ar.ordered, article)
+	ar.byAuthor[article.AuthorID] = ar.insertOrdered(ar.byAuthor[article.AuthorID], article)
+	for _, tag := range article.TagList {
+		ar.byTag[tag] = ar.insertOrdered(ar.byTag[tag], article)
+	}
+	return article, nil
+}
+
+func (ar *ArticleRepository) UpdateArticle(ctx context.Context, slug string, article realworld.Article) (realworld.Article, error) {
+	ar.Lock()
+	defer ar.Unlock()
+	record, ok := ar.memory[slug]
+	if !ok {
+		return realworld.Article{}, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	if article.Slug != slug {
+		if _, ok := ar.memory[article.Slug]; ok {
+			return realworld.Article{}, fmt.Errorf("%w with slug %s", realworld.ErrArticleAlreadyExists, article.Slug)
+		}
+		delete(ar.memory, slug)
+		ar.memory[article.Slug] = record
+		replace(ar.ordered, slug, article.Slug)
+		replace(ar.byAuthor[record.AuthorID], slug, article.Slug)
+		for _, tag := range record.TagList {
+			replace(ar.byTag[tag], slug, article.Slug)
+		}
+		for userID := range record.favoritedBy {
+			replace(ar.byFavoriter[userID], slug, article.Slug)
+		}
+	}
+	// NOTE: creation time, author and tags are fixed once created as indexes are ordered by them
+	article.TagList = record.TagList
+	article.ID = record.ID
+	article.CreatedAt = record.CreatedAt
+	article.AuthorID = record.AuthorID
+	record.Article = article
+	return record.clone(), nil
+}
+
+func (ar *ArticleRepository) DeleteArticle(ctx context.Context, slug string) error {
+	ar.Lock()
+	defer ar.Unlock()
+	record, ok := ar.memory[slug]
+	if !ok {
+		return fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	delete(ar.memory, slug)
+	ar.ordered = slices.DeleteFunc(ar.ordered, func(s string) bool { return s == slug })
+	ar.byAuthor[record.AuthorID] = slices.DeleteFunc(ar.byAuthor[record.AuthorID], func(s string) bool { return s == slug })
+	for _, tag := range record.TagList {
+		if ar.byTag[tag] = slices.DeleteFunc(ar.byTag[tag], func(s string) bool { return s == slug }); len(ar.byTag[tag]) == 0 {
+			delete(ar.byTag, tag)
+		}
+	}
+	for userID := range record.favoritedBy {
+		ar.unfavorite(record, userID)
+	}
+	ar.comments.deleteArticleComments(record.ID)
+	return nil
+}
+
+func (ar *ArticleRepository) FindArticleBySlug(ctx context.Context, slug string) (realworld.Article, error) {
+	ar.RLock()
+	defer ar.RUnlock()
+	record, ok := ar.memory[slug]
+	if !ok {
+		return realworld.Article{}, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	return record.clone(), nil
+}
+
+func (ar *ArticleRepository) ListArticles(ctx context.Context, query realworld.ArticleQuery) ([]realworld.Article, int, error) {
+	ar.RLock()
+	defer ar.RUnlock()
+	// NOTE: scan the smallest index among those query is filtered by
+	candidates, filters := ar.ordered, 0
+	for _, index := range []struct {
+		filter string
+		slugs  map[string][]string
+	}{
+		{query.Author, ar.byAuthor},
+		{query.Tag, ar.byTag},
+		{query.FavoritedBy, ar.byFavoriter},
+	} {
+		if index.filter == "" {
+			continue
+		}
+		if filters++; filters == 1 || len(index.slugs[index.filter]) < len(candidates) {
+			candidates = index.slugs[index.filter]
+		}
+	}
+	// NOTE: index of the only filter holds exactly the articles matched, so the page is sliced out of it
+	if filters <= 1 {
+		return ar.page(candidates, query.Page), len(candidates), nil
+	}
+	articles := make([]realworld.Article, 0, query.Limit)
+	count := 0
+	for i := len(candidates) - 1; 0 <= i; i-- {
+		record := ar.memory[candidates[i]]
+		if !record.matches(query) {
+			continue
+		}
+		if query.Offset <= count && len(articles) < query.Limit {
+			articles = append(articles, record.clone())
+		}
+		count++
+	}
+	return articles, count, nil
+}
+
+func (ar *ArticleRepository) ListFeed(ctx context.Context, query realworld.FeedQuery) ([]realworld.Article, int, error) {
+	ar.RLock()
+	defer ar.RUnlock()
+	// NOTE: merge newest first from each author's index only until the page is filled
+	heads := make([][]string, 0, len(query.Authors))
+	count := 0
+	for _, author := range query.Authors {
+		if slugs := ar.byAuthor[author]; 0 < len(slugs) {
+			heads = append(heads, slugs)
+			count += len(slugs)
+		}
+	}
+	articles := make([]realworld.Article, 0, query.Limit)
+	for skipped := 0; len(articles) < query.Limit && 0 < len(heads); {
+		newest := 0
+		for i := range heads {
+			if ar.newest(heads[i]).CreatedAt.After(ar.newest(heads[newest]).CreatedAt) {
+				newest = i
+			}
+		}
+		record := ar.newest(heads[newest])
+		if heads[newest] = heads[newest][:len(heads[newest])-1]; len(heads[newest]) == 0 {
+			heads = slices.Delete(heads, newest, newest+1)
+		}
+		if skipped < query.Offset {
+			skipped++
+			continue
+		}
+		articles = append(articles, record.clone())
+	}
+	return articles, count, nil
+}
+
+func (ar *ArticleRepository) FavoriteArticle(ctx context.Context, slug, userID string) (realworld.Article, error) {
+	ar.Lock()
+	defer ar.Unlock()
+	record, ok := ar.memory[slug]
+	if !ok {
+		return realworld.Article{}, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	if _, ok := record.favoritedBy[userID]; !ok {
+		record.favoritedBy[userID] = struct{}{}
+		ar.byFavoriter[userID] = ar.insertOrdered(ar.byFavoriter[userID], record.Article)
+	}
+	return record.clone(), nil
+}
+
+func (ar *ArticleRepository) UnfavoriteArticle(ctx context.Context, slug, userID string) (realworld.Article, error) {
+	ar.Lock()
+	defer ar.Unlock()
+	record, ok := ar.memory[slug]
+	if !ok {
+		return realworld.Article{}, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	ar.unfavorite(record, userID)
+	return record.clone(), nil
+}
+
+func (ar *ArticleRepository) IsFavorited(ctx context.Context, slug, userID string) (bool, error) {
+	ar.RLock()
+	defer ar.RUnlock()
+	record, ok := ar.memory[slug]
+	if !ok {
+		return false, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	_, ok = record.favoritedBy[userID]
+	return ok, nil
+}
+
+func (ar *ArticleRepository) FavoritedAmong(ctx context.Context, userID string, ids []int64) ([]int64, error) {
+	ar.RLock()
+	defer ar.RUnlock()
+	favorited := make([]int64, 0, len(ids))
+	for _, slug := range ar.byFavoriter[userID] {
+		if id := ar.memory[slug].ID; slices.Contains(ids, id) {
+			favorited = append(favorited, id)
+		}
+	}
+	return favorited, nil
+}
+
+// unfavorite removes userID from favorites of record and its index, which must be called with lock held
+func (ar *ArticleRepository) unfavorite(record *articleRecord, userID string) {
+	if _, ok := record.favoritedBy[userID]; !ok {
+		return
+	}
+	delete(record.favoritedBy, userID)
+	if ar.byFavoriter[userID] = slices.DeleteFunc(ar.byFavoriter[userID], func(s string) bool { return s == record.Slug }); len(ar.byFavoriter[userID]) == 0 {
+		delete(ar.byFavoriter, userID)
+	}
+}
+
+// page returns articles of page out of slugs ordered oldest first, newest first
+func (ar *ArticleRepository) page(slugs []string, page realworld.Page) []realworld.Article {
+	articles := make([]realworld.Article, 0, min(page.Limit, max(len(slugs)-page.Offset, 0)))
+	for i := len(slugs) - 1 - page.Offset; 0 <= i && len(articles) < page.Limit; i-- {
+		articles = append(articles, ar.memory[slugs[i]].clone())
+	}
+	return articles
+}
+
+func (ar *ArticleRepository) newest(slugs []string) *articleRecord {
+	return ar.memory[slugs[len(slugs)-1]]
+}
+
+// insertOrdered inserts slug of article into slugs keeping them ordered by creation time
+func (ar *ArticleRepository) insertOrdered(slugs []string, article realworld.Article) []string {
+	i, _ := slices.BinarySearchFunc(slugs, article, func(slug string, target realworld.Article) int {
+		if ar.memory[slug].CreatedAt.After(target.CreatedAt) {
+			return 1
+		}
+		return -1
+	})
+	return slices.Insert(slugs, i, article.Slug)
+}
+
+func (r *articleRecord) matches(query realworld.ArticleQuery) bool {
+	if query.Author != "" && r.AuthorID != query.Author {
+		return false
+	}
+	if query.Tag != "" && !slices.Contains(r.TagList, query.Tag) {
+		return false
+	}
+	if query.FavoritedBy != "" {
+		if _, ok := r.favoritedBy[query.FavoritedBy]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// clone must be called while holding lock as FavoritesCount is counted from favoritedBy
+func (r *articleRecord) clone() realworld.Article {
+	article := r.Article
+	article.TagList = slices.Clone(article.TagList)
+	article.FavoritesCount = len(r.favoritedBy)
+	return article
+}
+
+func replace(slugs []string, from, to string) {
+	if i := slices.Index(slugs, from); 0 <= i {
+		slugs[i] = to
+	}
+}
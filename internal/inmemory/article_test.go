@@ -0,0 +1,122 @@
+package inmemory_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/raeperd/realworld"
+	"github.com/raeperd/realworld/internal/inmemory"
+)
+
+func TestArticleRepository_ListArticles(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewArticleRepository(inmemory.NewCommentRepository())
+	now := time.Now()
+	for i, slug := range []string{"first", "second", "third"} {
+		_, err := repo.CreateArticle(ctx, realworld.Article{
+			Slug: slug, TagList: []string{slug, "all"}, AuthorID: "author-id", CreatedAt: now.Add(time.Duration(i) * time.Second),
+		})
+		be.NilErr(t, err)
+	}
+	for _, slug := range []string{"first", "third"} {
+		_, err := repo.FavoriteArticle(ctx, slug, "reader-id")
+		be.NilErr(t, err)
+	}
+	list := func(query realworld.ArticleQuery) ([]string, int) {
+		t.Helper()
+		if query.Limit == 0 {
+			query.Limit = 20
+		}
+		articles, count, err := repo.ListArticles(ctx, query)
+		be.NilErr(t, err)
+		slugs := make([]string, len(articles))
+		for i, article := range articles {
+			slugs[i] = article.Slug
+		}
+		return slugs, count
+	}
+
+	slugs, count := list(realworld.ArticleQuery{Page: realworld.Page{Limit: 1, Offset: 1}})
+	be.AllEqual(t, []string{"second"}, slugs)
+	be.Equal(t, 3, count)
+	slugs, count = list(realworld.ArticleQuery{Page: realworld.Page{Offset: 5}})
+	be.Equal(t, 0, len(slugs))
+	be.Equal(t, 3, count)
+	slugs, count = list(realworld.ArticleQuery{FavoritedBy: "reader-id"})
+	be.AllEqual(t, []string{"third", "first"}, slugs)
+	be.Equal(t, 2, count)
+	slugs, count = list(realworld.ArticleQuery{FavoritedBy: "reader-id", Tag: "first"})
+	be.AllEqual(t, []string{"first"}, slugs)
+	be.Equal(t, 1, count)
+	slugs, count = list(realworld.ArticleQuery{Author: "author-id", Tag: "all", Page: realworld.Page{Limit: 2}})
+	be.AllEqual(t, []string{"third", "second"}, slugs)
+	be.Equal(t, 3, count)
+
+	// NOTE: favorites index follows articles renamed, unfavorited and deleted
+	_, err := repo.UpdateArticle(ctx, "first", realworld.Article{Slug: "renamed"})
+	be.NilErr(t, err)
+	slugs, _ = list(realworld.ArticleQuery{FavoritedBy: "reader-id"})
+	be.AllEqual(t, []string{"third", "renamed"}, slugs)
+	_, err = repo.UnfavoriteArticle(ctx, "third", "reader-id")
+	be.NilErr(t, err)
+	be.NilErr(t, repo.DeleteArticle(ctx, "renamed"))
+	slugs, count = list(realworld.ArticleQuery{FavoritedBy: "reader-id"})
+	be.Equal(t, 0, len(slugs))
+	be.Equal(t, 0, count)
+}
+
+// BenchmarkArticleRepository_ListArticles shows listing a page takes about the same time regardless of number of articles
+func BenchmarkArticleRepository_ListArticles(b *testing.B) {
+	for _, size := range []int{1_000, 100_000} {
+		b.Run("articles="+strconv.Itoa(size), func(b *testing.B) {
+			ctx := context.Background()
+			repo := inmemory.NewArticleRepository(inmemory.NewCommentRepository())
+			now := time.Now()
+			for i := 0; i < size; i++ {
+				slug := "article" + strconv.Itoa(i)
+				_, err := repo.CreateArticle(ctx, realworld.Article{Slug: slug, AuthorID: "author-id", CreatedAt: now.Add(time.Duration(i))})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := repo.FavoriteArticle(ctx, slug, "reader-id"); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := repo.ListArticles(ctx, realworld.ArticleQuery{FavoritedBy: "reader-id", Page: realworld.Page{Limit: 20}}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestArticleRepository_DeleteArticle(t *testing.T) {
+	ctx := context.Background()
+	comments := inmemory.NewCommentRepository()
+	repo := inmemory.NewArticleRepository(comments)
+	deleted, err := repo.CreateArticle(ctx, realworld.Article{Slug: "deleted", AuthorID: "author-id"})
+	be.NilErr(t, err)
+	kept, err := repo.CreateArticle(ctx, realworld.Article{Slug: "kept", AuthorID: "author-id"})
+	be.NilErr(t, err)
+	comment, err := comments.CreateComment(ctx, realworld.Comment{ArticleID: deleted.ID, Body: "deleted"})
+	be.NilErr(t, err)
+	_, err = comments.CreateComment(ctx, realworld.Comment{ArticleID: kept.ID, Body: "kept"})
+	be.NilErr(t, err)
+
+	// NOTE: comments are deleted with article like ON DELETE CASCADE of SQL repositories
+	be.NilErr(t, repo.DeleteArticle(ctx, "deleted"))
+	list, err := comments.ListComments(ctx, deleted.ID)
+	be.NilErr(t, err)
+	be.Equal(t, 0, len(list))
+	_, err = comments.FindCommentByID(ctx, comment.ID)
+	be.True(t, errors.Is(err, realworld.ErrCommentNotFound))
+	list, err = comments.ListComments(ctx, kept.ID)
+	be.NilErr(t, err)
+	be.Equal(t, 1, len(list))
+}
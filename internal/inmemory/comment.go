@@ -0,0 +1,79 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/raeperd/realworld"
+)
+
+// CommentRepository implements [realworld.CommentRepository]
+type CommentRepository struct {
+	sync.RWMutex
+	memory map[int64]realworld.Comment
+	// byArticle holds comment ids by article id in creation order
+	byArticle map[int64][]int64
+	lastID    int64
+}
+
+func NewCommentRepository() *CommentRepository {
+	return &CommentRepository{
+		memory:    make(map[int64]realworld.Comment),
+		byArticle: make(map[int64][]int64),
+	}
+}
+
+func (cr *CommentRepository) CreateComment(ctx context.Context, comment realworld.Comment) (realworld.Comment, error) {
+	cr.Lock()
+	defer cr.Unlock()
+	cr.lastID++
+	comment.ID = cr.lastID
+	cr.memory[comment.ID] = comment
+	cr.byArticle[comment.ArticleID] = append(cr.byArticle[comment.ArticleID], comment.ID)
+	return comment, nil
+}
+
+func (cr *CommentRepository) FindCommentByID(ctx context.Context, id int64) (realworld.Comment, error) {
+	cr.RLock()
+	defer cr.RUnlock()
+	comment, ok := cr.memory[id]
+	if !ok {
+		return realworld.Comment{}, fmt.Errorf("%w with id %d", realworld.ErrCommentNotFound, id)
+	}
+	return comment, nil
+}
+
+func (cr *CommentRepository) ListComments(ctx context.Context, articleID int64) ([]realworld.Comment, error) {
+	cr.RLock()
+	defer cr.RUnlock()
+	ids := cr.byArticle[articleID]
+	comments := make([]realworld.Comment, len(ids))
+	for i, id := range ids {
+		comments[i] = cr.memory[id]
+	}
+	return comments, nil
+}
+
+func (cr *CommentRepository) DeleteComment(ctx context.Context, id int64) error {
+	cr.Lock()
+	defer cr.Unlock()
+	comment, ok := cr.memory[id]
+	if !ok {
+		return fmt.Errorf("%w with id %d", realworld.ErrCommentNotFound, id)
+	}
+	delete(cr.memory, id)
+	cr.byArticle[comment.ArticleID] = slices.DeleteFunc(cr.byArticle[comment.ArticleID], func(i int64) bool { return i == id })
+	return nil
+}
+
+// deleteArticleComments deletes every comment on article of articleID
+func (cr *CommentRepository) deleteArticleComments(articleID int64) {
+	cr.Lock()
+	defer cr.Unlock()
+	for _, id := range cr.byArticle[articleID] {
+		delete(cr.memory, id)
+	}
+	delete(cr.byArticle, articleID)
+}
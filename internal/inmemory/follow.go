@@ -0,0 +1,67 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+)
+
+// FollowRepository implements [realworld.FollowRepository]
+type FollowRepository struct {
+	sync.RWMutex
+	// memory maps follower id to set of followee ids
+	memory map[string]map[string]struct{}
+}
+
+func NewFollowRepository() *FollowRepository {
+	return &FollowRepository{
+		memory: make(map[string]map[string]struct{}),
+	}
+}
+
+func (fr *FollowRepository) Follow(ctx context.Context, follower, followee string) error {
+	fr.Lock()
+	defer fr.Unlock()
+	followees, ok := fr.memory[follower]
+	if !ok {
+		followees = make(map[string]struct{})
+		fr.memory[follower] = followees
+	}
+	followees[followee] = struct{}{}
+	return nil
+}
+
+func (fr *FollowRepository) Unfollow(ctx context.Context, follower, followee string) error {
+	fr.Lock()
+	defer fr.Unlock()
+	delete(fr.memory[follower], followee)
+	return nil
+}
+
+func (fr *FollowRepository) IsFollowing(ctx context.Context, follower, followee string) (bool, error) {
+	fr.RLock()
+	defer fr.RUnlock()
+	_, ok := fr.memory[follower][followee]
+	return ok, nil
+}
+
+func (fr *FollowRepository) Followees(ctx context.Context, follower string) ([]string, error) {
+	fr.RLock()
+	defer fr.RUnlock()
+	followees := make([]string, 0, len(fr.memory[follower]))
+	for followee := range fr.memory[follower] {
+		followees = append(followees, followee)
+	}
+	return followees, nil
+}
+
+func (fr *FollowRepository) FolloweesAmong(ctx context.Context, follower string, ids []string) ([]string, error) {
+	fr.RLock()
+	defer fr.RUnlock()
+	followees := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := fr.memory[follower][id]; ok {
+			followees = append(followees, id)
+		}
+	}
+	return followees, nil
+}
@@ -0,0 +1,19 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+)
+
+// ListTags implements [realworld.TagRepository] from the tag index of articles,
+// so that tags stay consistent with articles without another lock.
+func (ar *ArticleRepository) ListTags(ctx context.Context) ([]string, error) {
+	ar.RLock()
+	defer ar.RUnlock()
+	tags := make([]string, 0, len(ar.byTag))
+	for tag := range ar.byTag {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return tags, nil
+}
@@ -0,0 +1,158 @@
+package inmemory
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/raeperd/realworld"
+)
+
+// RefreshTokenRepository implements [realworld.RefreshTokenRepository].
+// Tokens expired and tokens of a family older than the one used last are dropped, so that memory stays bounded.
+type RefreshTokenRepository struct {
+	sync.RWMutex
+	memory map[string]realworld.RefreshToken
+	// byFamily holds hashes of refresh tokens by family in order of creation
+	byFamily map[string][]string
+	// expiries holds hashes by expiry, which may be dropped already
+	expiries expiries
+	now      func() time.Time
+}
+
+// NewRefreshTokenRepository returns repository dropping tokens expired by now, e.g. [time.Now]
+func NewRefreshTokenRepository(now func() time.Time) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		memory:   make(map[string]realworld.RefreshToken),
+		byFamily: make(map[string][]string),
+		now:      now,
+	}
+}
+
+func (tr *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token realworld.RefreshToken) error {
+	tr.Lock()
+	defer tr.Unlock()
+	tr.expiries.dropExpired(tr.now(), func(hash string, expiresAt time.Time) {
+		if expired, ok := tr.memory[hash]; ok && expired.ExpiresAt.Equal(expiresAt) {
+			tr.delete(expired)
+		}
+	})
+	tr.memory[token.Hash] = token
+	tr.byFamily[token.Family] = append(tr.byFamily[token.Family], token.Hash)
+	heap.Push(&tr.expiries, expiry{key: token.Hash, at: token.ExpiresAt})
+	return nil
+}
+
+func (tr *RefreshTokenRepository) UseRefreshToken(ctx context.Context, hash string) (realworld.RefreshToken, error) {
+	tr.Lock()
+	defer tr.Unlock()
+	token, ok := tr.memory[hash]
+	if !ok {
+		return realworld.RefreshToken{}, fmt.Errorf("%w not found", realworld.ErrInvalidRefreshToken)
+	}
+	used := token
+	used.Used = true
+	tr.memory[hash] = used
+	// NOTE: tokens older than the one used now are used already, and only the latest used one is kept to detect reuse.
+	// Replaying older ones is then rejected as not found rather than revoking the family.
+	if !token.Used {
+		hashes := tr.byFamily[token.Family]
+		i := slices.Index(hashes, hash)
+		for _, older := range hashes[:i] {
+			delete(tr.memory, older)
+		}
+		tr.byFamily[token.Family] = slices.Delete(hashes, 0, i)
+	}
+	return token, nil
+}
+
+func (tr *RefreshTokenRepository) RevokeTokenFamily(ctx context.Context, family string) error {
+	tr.Lock()
+	defer tr.Unlock()
+	for _, hash := range tr.byFamily[family] {
+		delete(tr.memory, hash)
+	}
+	delete(tr.byFamily, family)
+	return nil
+}
+
+// delete deletes token from memory and its family, which must be called with lock held
+func (tr *RefreshTokenRepository) delete(token realworld.RefreshToken) {
+	delete(tr.memory, token.Hash)
+	hashes := slices.DeleteFunc(tr.byFamily[token.Family], func(h string) bool { return h == token.Hash })
+	if len(hashes) == 0 {
+		delete(tr.byFamily, token.Family)
+		return
+	}
+	tr.byFamily[token.Family] = hashes
+}
+
+// RevokedTokenRepository implements [realworld.RevokedTokenRepository]
+type RevokedTokenRepository struct {
+	sync.RWMutex
+	// memory maps jti to when it expires
+	memory map[string]time.Time
+	// expiries holds jti by expiry, which may be revoked again with another expiry
+	expiries expiries
+	now      func() time.Time
+}
+
+// NewRevokedTokenRepository returns repository dropping tokens expired by now, e.g. [time.Now]
+func NewRevokedTokenRepository(now func() time.Time) *RevokedTokenRepository {
+	return &RevokedTokenRepository{
+		memory: make(map[string]time.Time),
+		now:    now,
+	}
+}
+
+func (rr *RevokedTokenRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	rr.Lock()
+	defer rr.Unlock()
+	// NOTE: entries of expired tokens are dropped on every revocation so that memory stays bounded
+	rr.expiries.dropExpired(rr.now(), func(revoked string, at time.Time) {
+		if expiry, ok := rr.memory[revoked]; ok && expiry.Equal(at) {
+			delete(rr.memory, revoked)
+		}
+	})
+	rr.memory[jti] = expiresAt
+	heap.Push(&rr.expiries, expiry{key: jti, at: expiresAt})
+	return nil
+}
+
+func (rr *RevokedTokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	rr.RLock()
+	defer rr.RUnlock()
+	_, ok := rr.memory[jti]
+	return ok, nil
+}
+
+// expiry is entry of [expiries] keyed by token hash or jti
+type expiry struct {
+	key string
+	at  time.Time
+}
+
+// expiries is min-heap of expiry by time, so that expired entries are found without scanning the others
+type expiries []expiry
+
+func (e expiries) Len() int           { return len(e) }
+func (e expiries) Less(i, j int) bool { return e[i].at.Before(e[j].at) }
+func (e expiries) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e *expiries) Push(x any)        { *e = append(*e, x.(expiry)) }
+func (e *expiries) Pop() any {
+	old := *e
+	x := old[len(old)-1]
+	*e = old[:len(old)-1]
+	return x
+}
+
+// dropExpired pops entries expired before now and calls drop with each of them
+func (e *expiries) dropExpired(now time.Time, drop func(key string, at time.Time)) {
+	for 0 < e.Len() && (*e)[0].at.Before(now) {
+		expired := heap.Pop(e).(expiry)
+		drop(expired.key, expired.at)
+	}
+}
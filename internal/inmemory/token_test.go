@@ -0,0 +1,66 @@
+package inmemory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/raeperd/realworld"
+	"github.com/raeperd/realworld/internal/inmemory"
+)
+
+func TestRefreshTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := inmemory.NewRefreshTokenRepository(func() time.Time { return now })
+
+	newToken := func(hash, family string) realworld.RefreshToken {
+		return realworld.RefreshToken{Hash: hash, Family: family, UserID: "alice", ExpiresAt: now.Add(time.Hour)}
+	}
+	be.NilErr(t, repo.CreateRefreshToken(ctx, newToken("first", "session")))
+	be.NilErr(t, repo.CreateRefreshToken(ctx, newToken("second", "session")))
+	_, err := repo.UseRefreshToken(ctx, "first")
+	be.NilErr(t, err)
+	_, err = repo.UseRefreshToken(ctx, "second")
+	be.NilErr(t, err)
+
+	// NOTE: older token of family is dropped once newer one is used, while the used one is kept to detect reuse
+	_, err = repo.UseRefreshToken(ctx, "first")
+	be.True(t, errors.Is(err, realworld.ErrInvalidRefreshToken))
+	reused, err := repo.UseRefreshToken(ctx, "second")
+	be.NilErr(t, err)
+	be.True(t, reused.Used)
+
+	// NOTE: expired tokens are dropped on creating another
+	now = now.Add(2 * time.Hour)
+	be.NilErr(t, repo.CreateRefreshToken(ctx, newToken("third", "another")))
+	_, err = repo.UseRefreshToken(ctx, "second")
+	be.True(t, errors.Is(err, realworld.ErrInvalidRefreshToken))
+	third, err := repo.UseRefreshToken(ctx, "third")
+	be.NilErr(t, err)
+	be.False(t, third.Used)
+}
+
+func TestRevokedTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := inmemory.NewRevokedTokenRepository(func() time.Time { return now })
+
+	be.NilErr(t, repo.RevokeToken(ctx, "short", now.Add(time.Minute)))
+	be.NilErr(t, repo.RevokeToken(ctx, "long", now.Add(time.Hour)))
+	// NOTE: revoking again extends expiry, so that earlier expiry does not drop it
+	be.NilErr(t, repo.RevokeToken(ctx, "short", now.Add(2*time.Hour)))
+
+	now = now.Add(90 * time.Minute)
+	be.NilErr(t, repo.RevokeToken(ctx, "another", now.Add(time.Hour)))
+	revoked, err := repo.IsTokenRevoked(ctx, "long")
+	be.NilErr(t, err)
+	be.False(t, revoked)
+	for _, jti := range []string{"short", "another"} {
+		revoked, err = repo.IsTokenRevoked(ctx, jti)
+		be.NilErr(t, err)
+		be.True(t, revoked)
+	}
+}
@@ -3,34 +3,109 @@ package inmemory
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/raeperd/realworld"
 )
 
 // UserRepository implements [realworld.UserRepository]
+// with email and username indexes looked up case-insensitively.
 type UserRepository struct {
 	sync.RWMutex
 	memory map[string]realworld.User
+	// byEmail and byUsername hold user id by lowercased email and username
+	byEmail    map[string]string
+	byUsername map[string]string
 }
 
 func NewUserRepository() *UserRepository {
 	return &UserRepository{
-		memory: make(map[string]realworld.User),
+		memory:     make(map[string]realworld.User),
+		byEmail:    make(map[string]string),
+		byUsername: make(map[string]string),
 	}
 }
 
 func (us *UserRepository) CreateUser(ctx context.Context, user realworld.User) (realworld.User, error) {
 	us.Lock()
-	us.memory[user.Email] = user
-	us.Unlock()
+	defer us.Unlock()
+	if _, ok := us.memory[user.ID]; ok {
+		return realworld.User{}, fmt.Errorf("%w with id %s", realworld.ErrUserAlreadyExists, user.ID)
+	}
+	if err := us.checkUnique(user); err != nil {
+		return realworld.User{}, err
+	}
+	us.index(user)
+	return user, nil
+}
+
+func (us *UserRepository) UpdateUser(ctx context.Context, user realworld.User) (realworld.User, error) {
+	us.Lock()
+	defer us.Unlock()
+	found, ok := us.memory[user.ID]
+	if !ok {
+		return realworld.User{}, fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, user.ID)
+	}
+	if err := us.checkUnique(user); err != nil {
+		return realworld.User{}, err
+	}
+	user.TokenVersion = found.TokenVersion
+	us.unindex(found)
+	us.index(user)
 	return user, nil
 }
 
+func (us *UserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	us.Lock()
+	defer us.Unlock()
+	user, ok := us.memory[id]
+	if !ok {
+		return fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, id)
+	}
+	user.TokenVersion++
+	us.memory[id] = user
+	return nil
+}
+
+// DeleteUser deletes user with id. Follows, articles and comments of the user are left to their repositories.
+func (us *UserRepository) DeleteUser(ctx context.Context, id string) error {
+	us.Lock()
+	defer us.Unlock()
+	found, ok := us.memory[id]
+	if !ok {
+		return fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, id)
+	}
+	us.unindex(found)
+	return nil
+}
+
+func (us *UserRepository) FindUserByID(ctx context.Context, id string) (realworld.User, error) {
+	us.RLock()
+	defer us.RUnlock()
+	user, ok := us.memory[id]
+	if !ok {
+		return realworld.User{}, fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, id)
+	}
+	return user, nil
+}
+
+func (us *UserRepository) FindUsersByIDs(ctx context.Context, ids []string) ([]realworld.User, error) {
+	us.RLock()
+	defer us.RUnlock()
+	users := make([]realworld.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := us.memory[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
 func (us *UserRepository) FindUserByEmail(ctx context.Context, email string) (realworld.User, error) {
 	us.RLock()
 	defer us.RUnlock()
-	user, ok := us.memory[email]
+	user, ok := us.find(us.byEmail, email)
 	if !ok {
 		return realworld.User{}, fmt.Errorf("%w with email %s", realworld.ErrUserNotFound, email)
 	}
@@ -40,10 +115,41 @@ func (us *UserRepository) FindUserByEmail(ctx context.Context, email string) (re
 func (us *UserRepository) FindUserByUsername(ctx context.Context, username string) (realworld.User, error) {
 	us.RLock()
 	defer us.RUnlock()
-	for _, user := range us.memory {
-		if user.Username == username {
-			return user, nil
-		}
+	user, ok := us.find(us.byUsername, username)
+	if !ok {
+		return realworld.User{}, fmt.Errorf("%w with username %s", realworld.ErrUserNotFound, username)
 	}
-	return realworld.User{}, fmt.Errorf("%w with username %s", realworld.ErrUserNotFound, username)
+	return user, nil
+}
+
+func (us *UserRepository) find(index map[string]string, key string) (realworld.User, bool) {
+	id, ok := index[strings.ToLower(key)]
+	if !ok {
+		return realworld.User{}, false
+	}
+	return us.memory[id], true
+}
+
+// checkUnique returns error if email or username of user is taken by other user
+func (us *UserRepository) checkUnique(user realworld.User) error {
+	if id, ok := us.byEmail[strings.ToLower(user.Email)]; ok && id != user.ID {
+		return realworld.ErrorUserAlreadyExists("email")
+	}
+	if id, ok := us.byUsername[strings.ToLower(user.Username)]; ok && id != user.ID {
+		return realworld.ErrorUserAlreadyExists("username")
+	}
+	return nil
+}
+
+// index and unindex keep memory and indexes consistent, which must be called with lock held
+func (us *UserRepository) index(user realworld.User) {
+	us.memory[user.ID] = user
+	us.byEmail[strings.ToLower(user.Email)] = user.ID
+	us.byUsername[strings.ToLower(user.Username)] = user.ID
+}
+
+func (us *UserRepository) unindex(user realworld.User) {
+	delete(us.memory, user.ID)
+	delete(us.byEmail, strings.ToLower(user.Email))
+	delete(us.byUsername, strings.ToLower(user.Username))
 }
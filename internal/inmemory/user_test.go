@@ -0,0 +1,113 @@
+package inmemory_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/carlmjohnson/be"
+	"github.com/raeperd/realworld"
+	"github.com/raeperd/realworld/internal/inmemory"
+)
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewUserRepository()
+
+	alice, err := repo.CreateUser(ctx, newUser("Alice"))
+	be.NilErr(t, err)
+	found, err := repo.FindUserByUsername(ctx, "ALICE")
+	be.NilErr(t, err)
+	be.Equal(t, alice, found)
+	found, err = repo.FindUserByEmail(ctx, "alice@EMAIL.com")
+	be.NilErr(t, err)
+	be.Equal(t, alice, found)
+
+	duplicated := newUser("alice")
+	duplicated.ID = "duplicated-id"
+	_, err = repo.CreateUser(ctx, duplicated)
+	be.True(t, errors.Is(err, realworld.ErrUserAlreadyExists))
+
+	renamed := alice
+	renamed.Username = "carol"
+	renamed.Email = "carol@email.com"
+	_, err = repo.UpdateUser(ctx, renamed)
+	be.NilErr(t, err)
+	_, err = repo.FindUserByUsername(ctx, "alice")
+	be.True(t, errors.Is(err, realworld.ErrUserNotFound))
+	_, err = repo.FindUserByEmail(ctx, "alice@email.com")
+	be.True(t, errors.Is(err, realworld.ErrUserNotFound))
+	found, err = repo.FindUserByUsername(ctx, "Carol")
+	be.NilErr(t, err)
+	be.Equal(t, alice.ID, found.ID)
+
+	be.NilErr(t, repo.IncrementTokenVersion(ctx, alice.ID))
+	renamed, err = repo.UpdateUser(ctx, renamed)
+	be.NilErr(t, err)
+	be.Equal(t, alice.TokenVersion+1, renamed.TokenVersion)
+
+	// NOTE: username and email freed by rename are available to others
+	other := newUser("alice")
+	other.ID = "other-alice-id"
+	_, err = repo.CreateUser(ctx, other)
+	be.NilErr(t, err)
+
+	be.NilErr(t, repo.DeleteUser(ctx, alice.ID))
+	_, err = repo.FindUserByUsername(ctx, "carol")
+	be.True(t, errors.Is(err, realworld.ErrUserNotFound))
+	_, err = repo.FindUserByID(ctx, alice.ID)
+	be.True(t, errors.Is(err, realworld.ErrUserNotFound))
+	be.True(t, errors.Is(repo.DeleteUser(ctx, alice.ID), realworld.ErrUserNotFound))
+}
+
+// BenchmarkUserRepository_FindUserByUsername shows lookup takes about the same time regardless of number of users
+func BenchmarkUserRepository_FindUserByUsername(b *testing.B) {
+	for _, size := range []int{1_000, 1_000_000} {
+		b.Run("users="+strconv.Itoa(size), func(b *testing.B) {
+			ctx := context.Background()
+			repo := newRepository(b, size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.FindUserByUsername(ctx, "user"+strconv.Itoa(i%size)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUserRepository_FindUserByEmail(b *testing.B) {
+	for _, size := range []int{1_000, 1_000_000} {
+		b.Run("users="+strconv.Itoa(size), func(b *testing.B) {
+			ctx := context.Background()
+			repo := newRepository(b, size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.FindUserByEmail(ctx, "user"+strconv.Itoa(i%size)+"@email.com"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func newRepository(b *testing.B, size int) *inmemory.UserRepository {
+	b.Helper()
+	repo := inmemory.NewUserRepository()
+	for i := 0; i < size; i++ {
+		if _, err := repo.CreateUser(context.Background(), newUser("user"+strconv.Itoa(i))); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func newUser(name string) realworld.User {
+	return realworld.User{
+		ID:       name + "-id",
+		Profile:  realworld.Profile{Username: name},
+		Email:    name + "@email.com",
+		Password: "hash",
+	}
+}
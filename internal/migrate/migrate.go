@@ -0,0 +1,191 @@
+// Package migrate applies versioned schema migrations embedded in binary, recording applied versions in database.
+//
+// Migrations are pairs of files named like 0001_create_users.up.sql and 0001_create_users.down.sql,
+// where the leading number is the version applied in ascending order.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Driver runs migrations on a database and keeps track of applied versions.
+type Driver interface {
+	// Lock blocks until other runners sharing the database release the lock, and returns func releasing it.
+	Lock(ctx context.Context) (unlock func() error, err error)
+	// Versions creates table of applied versions if not exists and returns applied versions in ascending order
+	Versions(ctx context.Context) ([]int64, error)
+	// Apply runs up script and records version, or runs down script and deletes version, in a single transaction.
+	Apply(ctx context.Context, migration Migration, up bool) error
+}
+
+// ErrNoApplied is returned on reverting migration when none is applied
+var ErrNoApplied = errors.New("migrate: no migration applied")
+
+type Migrator struct {
+	driver     Driver
+	migrations []Migration
+}
+
+// NewMigrator loads migrations from .sql files in root of fsys.
+func NewMigrator(driver Driver, fsys fs.FS) (Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return Migrator{}, err
+	}
+	return Migrator{driver: driver, migrations: migrations}, nil
+}
+
+// Load parses migrations from .sql files in root of fsys in ascending order of version.
+// Every version must have both of up and down script.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		version, name, up, err := parseFilename(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migrate: version %d has different names %s and %s", version, migration.Name, name)
+		}
+		if up {
+			migration.Up = string(script)
+		} else {
+			migration.Down = string(script)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migrate: version %d must have both up and down script", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+// parseFilename parses filename like 0001_create_users.up.sql
+func parseFilename(filename string) (version int64, name string, up bool, err error) {
+	base := strings.TrimSuffix(filename, ".sql")
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		base, up = strings.TrimSuffix(base, ".up"), true
+	case strings.HasSuffix(base, ".down"):
+		base = strings.TrimSuffix(base, ".down")
+	default:
+		return 0, "", false, fmt.Errorf("migrate: %s must end with .up.sql or .down.sql", filename)
+	}
+	prefix, name, _ := strings.Cut(base, "_")
+	version, err = strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", false, fmt.Errorf("migrate: %s must start with positive version", filename)
+	}
+	return version, name, up, nil
+}
+
+// Up applies every pending migration in ascending order and returns them
+func (m Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var migrated []Migration
+	err := m.locked(ctx, func(applied []int64) error {
+		for _, migration := range m.migrations {
+			if _, ok := slices.BinarySearch(applied, migration.Version); ok {
+				continue
+			}
+			if err := m.driver.Apply(ctx, migration, true); err != nil {
+				return fmt.Errorf("migrate: apply %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			migrated = append(migrated, migration)
+		}
+		return nil
+	})
+	return migrated, err
+}
+
+// Down reverts the latest applied migration and returns it
+func (m Migrator) Down(ctx context.Context) (Migration, error) {
+	var reverted Migration
+	err := m.locked(ctx, func(applied []int64) error {
+		if len(applied) == 0 {
+			return ErrNoApplied
+		}
+		latest := applied[len(applied)-1]
+		i := slices.IndexFunc(m.migrations, func(migration Migration) bool { return migration.Version == latest })
+		if i < 0 {
+			return fmt.Errorf("migrate: applied version %d is unknown to this binary", latest)
+		}
+		reverted = m.migrations[i]
+		if err := m.driver.Apply(ctx, reverted, false); err != nil {
+			return fmt.Errorf("migrate: revert %d_%s: %w", reverted.Version, reverted.Name, err)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+type Status struct {
+	Migration
+	Applied bool
+}
+
+// Status reports whether each migration is applied in ascending order
+func (m Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.driver.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		_, ok := slices.BinarySearch(applied, migration.Version)
+		statuses[i] = Status{Migration: migration, Applied: ok}
+	}
+	return statuses, nil
+}
+
+// locked runs f with applied versions while holding lock of driver
+func (m Migrator) locked(ctx context.Context, f func(applied []int64) error) (err error) {
+	unlock, err := m.driver.Lock(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: lock: %w", err)
+	}
+	defer func() {
+		if unlockErr := unlock(); unlockErr != nil {
+			err = errors.Join(err, fmt.Errorf("migrate: unlock: %w", unlockErr))
+		}
+	}()
+	applied, err := m.driver.Versions(ctx)
+	if err != nil {
+		return err
+	}
+	return f(applied)
+}
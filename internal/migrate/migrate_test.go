@@ -0,0 +1,35 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/carlmjohnson/be"
+	"github.com/raeperd/realworld/internal/migrate"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := migrate.Load(fstest.MapFS{
+		"0010_add_index.up.sql":      {Data: []byte("CREATE INDEX")},
+		"0010_add_index.down.sql":    {Data: []byte("DROP INDEX")},
+		"0002_create_users.up.sql":   {Data: []byte("CREATE TABLE")},
+		"0002_create_users.down.sql": {Data: []byte("DROP TABLE")},
+		"README.md":                  {Data: []byte("ignored")},
+	})
+	be.NilErr(t, err)
+	be.AllEqual(t, []migrate.Migration{
+		{Version: 2, Name: "create_users", Up: "CREATE TABLE", Down: "DROP TABLE"},
+		{Version: 10, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+	}, migrations)
+
+	badcases := []fstest.MapFS{
+		{"0001_init.up.sql": {Data: []byte("CREATE TABLE")}},
+		{"init.up.sql": {Data: []byte("CREATE TABLE")}, "init.down.sql": {Data: []byte("DROP TABLE")}},
+		{"0001_init.sql": {Data: []byte("CREATE TABLE")}},
+		{"0001_init.up.sql": {Data: []byte("CREATE TABLE")}, "0001_other.down.sql": {Data: []byte("DROP TABLE")}},
+	}
+	for _, fsys := range badcases {
+		_, err := migrate.Load(fsys)
+		be.Nonzero(t, err)
+	}
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/raeperd/realworld"
+)
+
+// ArticleRepository implements [realworld.ArticleRepository] and [realworld.TagRepository]
+type ArticleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewArticleRepository(pool *pgxpool.Pool) *ArticleRepository {
+	return &ArticleRepository{pool: pool}
+}
+
+// selectArticle selects columns scanned by scanArticle, with tags aggregated in order of position
+const selectArticle = `SELECT a.id, a.slug, a.title, a.description, a.body, a.author_id, a.created_at, a.updated_at,
+	(SELECT COALESCE(array_agg(tag ORDER BY position), '{}') FROM article_tags WHERE article_id = a.id),
+	(SELECT COUNT(*) FROM favorites WHERE article_id = a.id)
+FROM articles a `
+
+func (ar *ArticleRepository) CreateArticle(ctx context.Context, article realworld.Article) (realworld.Article, error) {
+	err := pgx.BeginFunc(ctx, ar.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`INSERT INTO articles (slug, title, description, body, author_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			article.Slug, article.Title, article.Description, article.Body, article.AuthorID, article.CreatedAt, article.UpdatedAt,
+		).Scan(&article.ID)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w with slug %s", realworld.ErrArticleAlreadyExists, article.Slug)
+		}
+		if err != nil {
+			return err
+		}
+		for i, tag := range article.TagList {
+			if _, err := tx.Exec(ctx, `INSERT INTO article_tags (article_id, tag, position) VALUES ($1, $2, $3)`, article.ID, tag, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return realworld.Article{}, err
+	}
+	return article, nil
+}
+
+func (ar *ArticleRepository) UpdateArticle(ctx context.Context, slug string, article realworld.Article) (realworld.Article, error) {
+	var updated realworld.Article
+	err := pgx.BeginFunc(ctx, ar.pool, func(tx pgx.Tx) error {
+		// NOTE: creation time, author and tags are fixed once created as in inmemory implementation
+		tag, err := tx.Exec(ctx,
+			`UPDATE articles SET slug = $1, title = $2, description = $3, body = $4, updated_at = $5 WHERE slug = $6`,
+			article.Slug, article.Title, article.Description, article.Body, article.UpdatedAt, slug,
+		)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w with slug %s", realworld.ErrArticleAlreadyExists, article.Slug)
+		}
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+		}
+		updated, err = findArticleBySlug(ctx, tx, article.Slug)
+		return err
+	})
+	if err != nil {
+		return realworld.Article{}, err
+	}
+	return updated, nil
+}
+
+func (ar *ArticleRepository) DeleteArticle(ctx context.Context, slug string) error {
+	tag, err := ar.pool.Exec(ctx, `DELETE FROM articles WHERE slug = $1`, slug)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	return nil
+}
+
+func (ar *ArticleRepository) FindArticleBySlug(ctx context.Context, slug string) (realworld.Article, error) {
+	return findArticleBySlug(ctx, ar.pool, slug)
+}
+
+func (ar *ArticleRepository) ListArticles(ctx context.Context, query realworld.ArticleQuery) ([]realworld.Article, int, error) {
+	where := `WHERE ($1::text = '' OR a.author_id = $1)
+	AND ($2::text = '' OR EXISTS (SELECT 1 FROM article_tags WHERE article_id = a.id AND tag = $2))
+	AND ($3::text = '' OR EXISTS (SELECT 1 FROM favorites WHERE article_id = a.id AND user_id = $3)) `
+	return ar.listArticles(ctx, where, []any{query.Author, query.Tag, query.FavoritedBy}, query.Page)
+}
+
+func (ar *ArticleRepository) ListFeed(ctx context.Context, query realworld.FeedQuery) ([]realworld.Article, int, error) {
+	return ar.listArticles(ctx, `WHERE a.author_id = ANY($1) `, []any{query.Authors}, query.Page)
+}
+
+// listArticles returns a page of articles matching where clause newest first and total count of them.
+// Placeholders of limit and offset are numbered after args.
+func (ar *ArticleRepository) listArticles(ctx context.Context, where string, args []any, page realworld.Page) ([]realworld.Article, int, error) {
+	var count int
+	if err := ar.pool.QueryRow(ctx, `SELECT COUNT(*) FROM articles a `+where, args...).Scan(&count); err != nil {
+		return nil, 0, err
+	}
+	rows, err := ar.pool.Query(ctx,
+		selectArticle+where+fmt.Sprintf(`ORDER BY a.created_at DESC, a.id DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2),
+		append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	articles, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (realworld.Article, error) { return scanArticle(row) })
+	if err != nil {
+		return nil, 0, err
+	}
+	return articles, count, nil
+}
+
+func (ar *ArticleRepository) FavoriteArticle(ctx context.Context, slug, userID string) (realworld.Article, error) {
+	return ar.changeFavorite(ctx, slug, `INSERT INTO favorites (article_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, userID)
+}
+
+func (ar *ArticleRepository) UnfavoriteArticle(ctx context.Context, slug, userID string) (realworld.Article, error) {
+	return ar.changeFavorite(ctx, slug, `DELETE FROM favorites WHERE article_id = $1 AND user_id = $2`, userID)
+}
+
+// changeFavorite executes statement with article id and user id, returning article with updated favorites count
+func (ar *ArticleRepository) changeFavorite(ctx context.Context, slug, statement, userID string) (realworld.Article, error) {
+	var article realworld.Article
+	err := pgx.BeginFunc(ctx, ar.pool, func(tx pgx.Tx) error {
+		id, err := articleIDBySlug(ctx, tx, slug)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, statement, id, userID); err != nil {
+			return err
+		}
+		article, err = findArticleBySlug(ctx, tx, slug)
+		return err
+	})
+	if err != nil {
+		return realworld.Article{}, err
+	}
+	return article, nil
+}
+
+func (ar *ArticleRepository) IsFavorited(ctx context.Context, slug, userID string) (bool, error) {
+	id, err := articleIDBySlug(ctx, ar.pool, slug)
+	if err != nil {
+		return false, err
+	}
+	var favorited bool
+	err = ar.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM favorites WHERE article_id = $1 AND user_id = $2)`, id, userID,
+	).Scan(&favorited)
+	return favorited, err
+}
+
+func (ar *ArticleRepository) FavoritedAmong(ctx context.Context, userID string, ids []int64) ([]int64, error) {
+	rows, err := ar.pool.Query(ctx, `SELECT article_id FROM favorites WHERE user_id = $1 AND article_id = ANY($2)`, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+func (ar *ArticleRepository) ListTags(ctx context.Context) ([]string, error) {
+	rows, err := ar.pool.Query(ctx, `SELECT DISTINCT tag FROM article_tags ORDER BY tag`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func articleIDBySlug(ctx context.Context, q querier, slug string) (int64, error) {
+	var id int64
+	err := q.QueryRow(ctx, `SELECT id FROM articles WHERE slug = $1`, slug).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	return id, err
+}
+
+func findArticleBySlug(ctx context.Context, q querier, slug string) (realworld.Article, error) {
+	article, err := scanArticle(q.QueryRow(ctx, selectArticle+`WHERE a.slug = $1`, slug))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return realworld.Article{}, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	return article, err
+}
+
+func scanArticle(row pgx.Row) (realworld.Article, error) {
+	var article realworld.Article
+	err := row.Scan(&article.ID, &article.Slug, &article.Title, &article.Description, &article.Body, &article.AuthorID,
+		&article.CreatedAt, &article.UpdatedAt, &article.TagList, &article.FavoritesCount)
+	if err != nil {
+		return realworld.Article{}, err
+	}
+	return article, nil
+}
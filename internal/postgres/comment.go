@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/raeperd/realworld"
+)
+
+// CommentRepository implements [realworld.CommentRepository]
+type CommentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCommentRepository(pool *pgxpool.Pool) *CommentRepository {
+	return &CommentRepository{pool: pool}
+}
+
+const selectComment = `SELECT id, article_id, body, author_id, created_at, updated_at FROM comments `
+
+func (cr *CommentRepository) CreateComment(ctx context.Context, comment realworld.Comment) (realworld.Comment, error) {
+	err := cr.pool.QueryRow(ctx,
+		`INSERT INTO comments (article_id, body, author_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		comment.ArticleID, comment.Body, comment.AuthorID, comment.CreatedAt, comment.UpdatedAt,
+	).Scan(&comment.ID)
+	if err != nil {
+		return realworld.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (cr *CommentRepository) FindCommentByID(ctx context.Context, id int64) (realworld.Comment, error) {
+	comment, err := scanComment(cr.pool.QueryRow(ctx, selectComment+`WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return realworld.Comment{}, fmt.Errorf("%w with id %d", realworld.ErrCommentNotFound, id)
+	}
+	return comment, err
+}
+
+func (cr *CommentRepository) ListComments(ctx context.Context, articleID int64) ([]realworld.Comment, error) {
+	rows, err := cr.pool.Query(ctx, selectComment+`WHERE article_id = $1 ORDER BY id`, articleID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (realworld.Comment, error) { return scanComment(row) })
+}
+
+func (cr *CommentRepository) DeleteComment(ctx context.Context, id int64) error {
+	tag, err := cr.pool.Exec(ctx, `DELETE FROM comments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w with id %d", realworld.ErrCommentNotFound, id)
+	}
+	return nil
+}
+
+func scanComment(row pgx.Row) (realworld.Comment, error) {
+	var comment realworld.Comment
+	err := row.Scan(&comment.ID, &comment.ArticleID, &comment.Body, &comment.AuthorID, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		return realworld.Comment{}, err
+	}
+	return comment, nil
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FollowRepository implements [realworld.FollowRepository]
+type FollowRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewFollowRepository(pool *pgxpool.Pool) *FollowRepository {
+	return &FollowRepository{pool: pool}
+}
+
+func (fr *FollowRepository) Follow(ctx context.Context, follower, followee string) error {
+	_, err := fr.pool.Exec(ctx, `INSERT INTO follows (follower_id, followee_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, follower, followee)
+	return err
+}
+
+func (fr *FollowRepository) Unfollow(ctx context.Context, follower, followee string) error {
+	_, err := fr.pool.Exec(ctx, `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`, follower, followee)
+	return err
+}
+
+func (fr *FollowRepository) IsFollowing(ctx context.Context, follower, followee string) (bool, error) {
+	var following bool
+	err := fr.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2)`, follower, followee,
+	).Scan(&following)
+	return following, err
+}
+
+func (fr *FollowRepository) Followees(ctx context.Context, follower string) ([]string, error) {
+	rows, err := fr.pool.Query(ctx, `SELECT followee_id FROM follows WHERE follower_id = $1`, follower)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (fr *FollowRepository) FolloweesAmong(ctx context.Context, follower string, ids []string) ([]string, error) {
+	rows, err := fr.pool.Query(ctx, `SELECT followee_id FROM follows WHERE follower_id = $1 AND followee_id = ANY($2)`, follower, ids)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
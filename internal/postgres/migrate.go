@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/raeperd/realworld/internal/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationLockKey is key of advisory lock held by migration runner
+const migrationLockKey = 7_303_820_510_404
+
+// NewMigrator returns migrator of schema embedded in migrations directory, which is not safe for concurrent use.
+// Replicas migrating at once are serialized by advisory lock instead.
+func NewMigrator(pool *pgxpool.Pool) (migrate.Migrator, error) {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return migrate.Migrator{}, err
+	}
+	return migrate.NewMigrator(&migrationDriver{pool: pool}, sub)
+}
+
+// migrationDriver implements [migrate.Driver]
+type migrationDriver struct {
+	pool *pgxpool.Pool
+	// locked is connection holding the lock between Lock and unlock, which Versions and Apply run on
+	// instead of pool, so that migrating never waits for another connection of pool that may have none left.
+	locked *pgxpool.Conn
+}
+
+// migrationConn is either *pgxpool.Pool or *pgxpool.Conn
+type migrationConn interface {
+	querier
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Lock holds session level advisory lock on a connection taken from pool until unlocked,
+// so that replicas starting at once apply each migration only once.
+func (d *migrationDriver) Lock(ctx context.Context) (func() error, error) {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	d.locked = conn
+	return func() error {
+		d.locked = nil
+		defer conn.Release()
+		// NOTE: unlock must not be canceled along with ctx, or lock outlives the runner in pooled connection
+		_, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+		return err
+	}, nil
+}
+
+func (d *migrationDriver) conn() migrationConn {
+	if d.locked != nil {
+		return d.locked
+	}
+	return d.pool
+}
+
+func (d *migrationDriver) Versions(ctx context.Context) ([]int64, error) {
+	_, err := d.conn().Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT      PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.conn().Query(ctx, `SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+func (d *migrationDriver) Apply(ctx context.Context, migration migrate.Migration, up bool) error {
+	return pgx.BeginFunc(ctx, d.conn(), func(tx pgx.Tx) error {
+		if up {
+			if _, err := tx.Exec(ctx, migration.Up); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, migration.Version)
+			return err
+		}
+		if _, err := tx.Exec(ctx, migration.Down); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+		return err
+	})
+}
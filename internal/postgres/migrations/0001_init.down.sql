@@ -0,0 +1,8 @@
+DROP TABLE revoked_tokens;
+DROP TABLE refresh_tokens;
+DROP TABLE comments;
+DROP TABLE favorites;
+DROP TABLE article_tags;
+DROP TABLE articles;
+DROP TABLE follows;
+DROP TABLE users;
@@ -0,0 +1,71 @@
+CREATE TABLE users (
+    id            BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    email         TEXT    NOT NULL UNIQUE,
+    username      TEXT    NOT NULL UNIQUE,
+    password      TEXT    NOT NULL,
+    bio           TEXT    NOT NULL DEFAULT '',
+    image         TEXT    NOT NULL DEFAULT '',
+    token_version INTEGER NOT NULL DEFAULT 0
+);
+
+-- NOTE: users are referenced by email as in the domain, which cascades on update of email
+CREATE TABLE follows (
+    follower TEXT NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    followee TEXT NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (follower, followee)
+);
+
+CREATE TABLE articles (
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    slug         TEXT        NOT NULL UNIQUE,
+    title        TEXT        NOT NULL,
+    description  TEXT        NOT NULL,
+    body         TEXT        NOT NULL,
+    author_email TEXT        NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX articles_created_at ON articles (created_at);
+CREATE INDEX articles_author_email ON articles (author_email, created_at);
+
+CREATE TABLE article_tags (
+    article_id BIGINT  NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    tag        TEXT    NOT NULL,
+    -- position keeps order of tag list
+    position   INTEGER NOT NULL,
+    PRIMARY KEY (article_id, tag)
+);
+CREATE INDEX article_tags_tag ON article_tags (tag);
+
+CREATE TABLE favorites (
+    article_id BIGINT NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    email      TEXT   NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (article_id, email)
+);
+CREATE INDEX favorites_email ON favorites (email);
+
+CREATE TABLE comments (
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    article_id   BIGINT      NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    body         TEXT        NOT NULL,
+    author_email TEXT        NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX comments_article_id ON comments (article_id);
+
+CREATE TABLE refresh_tokens (
+    hash          TEXT        PRIMARY KEY,
+    family        TEXT        NOT NULL,
+    email         TEXT        NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    expires_at    TIMESTAMPTZ NOT NULL,
+    token_version INTEGER     NOT NULL,
+    -- uses counts rotations, so that more than one means reuse
+    uses          INTEGER     NOT NULL DEFAULT 0
+);
+CREATE INDEX refresh_tokens_family ON refresh_tokens (family);
+
+CREATE TABLE revoked_tokens (
+    jti        TEXT        PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
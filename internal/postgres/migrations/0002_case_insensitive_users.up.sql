@@ -0,0 +1,4 @@
+-- NOTE: email and username are unique regardless of case like in-memory repository. Migration fails
+-- if existing users differ only in case, which must be resolved by hand before upgrading.
+CREATE UNIQUE INDEX users_email_lower ON users (lower(email));
+CREATE UNIQUE INDEX users_username_lower ON users (lower(username));
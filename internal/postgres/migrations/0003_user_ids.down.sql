@@ -0,0 +1,48 @@
+ALTER TABLE refresh_tokens ADD COLUMN email TEXT;
+UPDATE refresh_tokens SET email = (SELECT email FROM users WHERE id = refresh_tokens.user_id);
+ALTER TABLE refresh_tokens DROP COLUMN user_id;
+ALTER TABLE refresh_tokens
+    ALTER COLUMN email SET NOT NULL,
+    ADD FOREIGN KEY (email) REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE comments ADD COLUMN author_email TEXT;
+UPDATE comments SET author_email = (SELECT email FROM users WHERE id = comments.author_id);
+ALTER TABLE comments DROP COLUMN author_id;
+ALTER TABLE comments
+    ALTER COLUMN author_email SET NOT NULL,
+    ADD FOREIGN KEY (author_email) REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE favorites ADD COLUMN email TEXT;
+UPDATE favorites SET email = (SELECT email FROM users WHERE id = favorites.user_id);
+ALTER TABLE favorites DROP COLUMN user_id;
+ALTER TABLE favorites
+    ALTER COLUMN email SET NOT NULL,
+    ADD PRIMARY KEY (article_id, email),
+    ADD FOREIGN KEY (email) REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE INDEX favorites_email ON favorites (email);
+
+ALTER TABLE articles ADD COLUMN author_email TEXT;
+UPDATE articles SET author_email = (SELECT email FROM users WHERE id = articles.author_id);
+ALTER TABLE articles DROP COLUMN author_id;
+ALTER TABLE articles
+    ALTER COLUMN author_email SET NOT NULL,
+    ADD FOREIGN KEY (author_email) REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE INDEX articles_author_email ON articles (author_email, created_at);
+
+ALTER TABLE follows ADD COLUMN follower TEXT, ADD COLUMN followee TEXT;
+UPDATE follows SET
+    follower = (SELECT email FROM users WHERE id = follows.follower_id),
+    followee = (SELECT email FROM users WHERE id = follows.followee_id);
+ALTER TABLE follows DROP COLUMN follower_id, DROP COLUMN followee_id;
+ALTER TABLE follows
+    ALTER COLUMN follower SET NOT NULL,
+    ALTER COLUMN followee SET NOT NULL,
+    ADD PRIMARY KEY (follower, followee),
+    ADD FOREIGN KEY (follower) REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    ADD FOREIGN KEY (followee) REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- NOTE: users are given integer ids again, as identity column fills existing rows
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users RENAME COLUMN id TO uuid;
+ALTER TABLE users ADD COLUMN id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY;
+ALTER TABLE users DROP COLUMN uuid;
@@ -0,0 +1,46 @@
+-- NOTE: existing users are given random UUIDv4 while users created from now on get UUIDv7 from application
+ALTER TABLE users ALTER COLUMN id DROP IDENTITY;
+ALTER TABLE users ALTER COLUMN id TYPE TEXT USING gen_random_uuid()::text;
+
+ALTER TABLE follows ADD COLUMN follower_id TEXT, ADD COLUMN followee_id TEXT;
+UPDATE follows SET
+    follower_id = (SELECT id FROM users WHERE email = follows.follower),
+    followee_id = (SELECT id FROM users WHERE email = follows.followee);
+ALTER TABLE follows DROP COLUMN follower, DROP COLUMN followee;
+ALTER TABLE follows
+    ALTER COLUMN follower_id SET NOT NULL,
+    ALTER COLUMN followee_id SET NOT NULL,
+    ADD PRIMARY KEY (follower_id, followee_id),
+    ADD FOREIGN KEY (follower_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (followee_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE articles ADD COLUMN author_id TEXT;
+UPDATE articles SET author_id = (SELECT id FROM users WHERE email = articles.author_email);
+ALTER TABLE articles DROP COLUMN author_email;
+ALTER TABLE articles
+    ALTER COLUMN author_id SET NOT NULL,
+    ADD FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE;
+CREATE INDEX articles_author_id ON articles (author_id, created_at);
+
+ALTER TABLE favorites ADD COLUMN user_id TEXT;
+UPDATE favorites SET user_id = (SELECT id FROM users WHERE email = favorites.email);
+ALTER TABLE favorites DROP COLUMN email;
+ALTER TABLE favorites
+    ALTER COLUMN user_id SET NOT NULL,
+    ADD PRIMARY KEY (article_id, user_id),
+    ADD FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+CREATE INDEX favorites_user_id ON favorites (user_id);
+
+ALTER TABLE comments ADD COLUMN author_id TEXT;
+UPDATE comments SET author_id = (SELECT id FROM users WHERE email = comments.author_email);
+ALTER TABLE comments DROP COLUMN author_email;
+ALTER TABLE comments
+    ALTER COLUMN author_id SET NOT NULL,
+    ADD FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE refresh_tokens ADD COLUMN user_id TEXT;
+UPDATE refresh_tokens SET user_id = (SELECT id FROM users WHERE email = refresh_tokens.email);
+ALTER TABLE refresh_tokens DROP COLUMN email;
+ALTER TABLE refresh_tokens
+    ALTER COLUMN user_id SET NOT NULL,
+    ADD FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/raeperd/realworld"
+)
+
+// RefreshTokenRepository implements [realworld.RefreshTokenRepository]
+type RefreshTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRefreshTokenRepository(pool *pgxpool.Pool) *RefreshTokenRepository {
+	return &RefreshTokenRepository{pool: pool}
+}
+
+func (tr *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token realworld.RefreshToken) error {
+	_, err := tr.pool.Exec(ctx,
+		`INSERT INTO refresh_tokens (hash, family, user_id, expires_at, token_version) VALUES ($1, $2, $3, $4, $5)`,
+		token.Hash, token.Family, token.UserID, token.ExpiresAt, token.TokenVersion,
+	)
+	return err
+}
+
+func (tr *RefreshTokenRepository) UseRefreshToken(ctx context.Context, hash string) (realworld.RefreshToken, error) {
+	// NOTE: update locks the row, so that only the first of concurrent uses sees uses of 1
+	var token realworld.RefreshToken
+	var uses int
+	err := tr.pool.QueryRow(ctx,
+		`UPDATE refresh_tokens SET uses = uses + 1 WHERE hash = $1 RETURNING hash, family, user_id, expires_at, token_version, uses`, hash,
+	).Scan(&token.Hash, &token.Family, &token.UserID, &token.ExpiresAt, &token.TokenVersion, &uses)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return realworld.RefreshToken{}, fmt.Errorf("%w not found", realworld.ErrInvalidRefreshToken)
+	}
+	if err != nil {
+		return realworld.RefreshToken{}, err
+	}
+	token.Used = uses > 1
+	return token, nil
+}
+
+func (tr *RefreshTokenRepository) RevokeTokenFamily(ctx context.Context, family string) error {
+	_, err := tr.pool.Exec(ctx, `DELETE FROM refresh_tokens WHERE family = $1`, family)
+	return err
+}
+
+// RevokedTokenRepository implements [realworld.RevokedTokenRepository]
+type RevokedTokenRepository struct {
+	pool *pgxpool.Pool
+	now  func() time.Time
+}
+
+// NewRevokedTokenRepository returns repository dropping tokens expired by now, e.g. [time.Now]
+func NewRevokedTokenRepository(pool *pgxpool.Pool, now func() time.Time) *RevokedTokenRepository {
+	return &RevokedTokenRepository{pool: pool, now: now}
+}
+
+func (rr *RevokedTokenRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	return pgx.BeginFunc(ctx, rr.pool, func(tx pgx.Tx) error {
+		// NOTE: rows of expired tokens are dropped on every revocation so that table stays bounded
+		if _, err := tx.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, rr.now()); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO UPDATE SET expires_at = EXCLUDED.expires_at`,
+			jti, expiresAt)
+		return err
+	})
+}
+
+func (rr *RevokedTokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := rr.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti).Scan(&revoked)
+	return revoked, err
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/raeperd/realworld"
+)
+
+// UserRepository implements [realworld.UserRepository]
+type UserRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
+	return &UserRepository{pool: pool}
+}
+
+func (ur *UserRepository) CreateUser(ctx context.Context, user realworld.User) (realworld.User, error) {
+	_, err := ur.pool.Exec(ctx,
+		`INSERT INTO users (id, email, username, password, bio, image, token_version) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		user.ID, user.Email, user.Username, user.Password, user.Bio, user.Image, user.TokenVersion,
+	)
+	if isUniqueViolation(err) {
+		return realworld.User{}, userAlreadyExists(err)
+	}
+	if err != nil {
+		return realworld.User{}, err
+	}
+	return user, nil
+}
+
+func (ur *UserRepository) UpdateUser(ctx context.Context, user realworld.User) (realworld.User, error) {
+	err := ur.pool.QueryRow(ctx,
+		`UPDATE users SET email = $1, username = $2, password = $3, bio = $4, image = $5 WHERE id = $6 RETURNING token_version`,
+		user.Email, user.Username, user.Password, user.Bio, user.Image, user.ID,
+	).Scan(&user.TokenVersion)
+	if isUniqueViolation(err) {
+		return realworld.User{}, userAlreadyExists(err)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return realworld.User{}, fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, user.ID)
+	}
+	if err != nil {
+		return realworld.User{}, err
+	}
+	return user, nil
+}
+
+func (ur *UserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	tag, err := ur.pool.Exec(ctx, `UPDATE users SET token_version = token_version + 1 WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, id)
+	}
+	return nil
+}
+
+func (ur *UserRepository) FindUserByID(ctx context.Context, id string) (realworld.User, error) {
+	user, err := ur.findUser(ctx, "id = $1", id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return realworld.User{}, fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, id)
+	}
+	return user, err
+}
+
+func (ur *UserRepository) FindUsersByIDs(ctx context.Context, ids []string) ([]realworld.User, error) {
+	rows, err := ur.pool.Query(ctx, `SELECT id, email, username, password, bio, image, token_version FROM users WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (realworld.User, error) {
+		var user realworld.User
+		err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.Bio, &user.Image, &user.TokenVersion)
+		return user, err
+	})
+}
+
+func (ur *UserRepository) FindUserByEmail(ctx context.Context, email string) (realworld.User, error) {
+	user, err := ur.findUser(ctx, "lower(email) = lower($1)", email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return realworld.User{}, fmt.Errorf("%w with email %s", realworld.ErrUserNotFound, email)
+	}
+	return user, err
+}
+
+func (ur *UserRepository) FindUserByUsername(ctx context.Context, username string) (realworld.User, error) {
+	user, err := ur.findUser(ctx, "lower(username) = lower($1)", username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return realworld.User{}, fmt.Errorf("%w with username %s", realworld.ErrUserNotFound, username)
+	}
+	return user, err
+}
+
+// findUser finds user by condition on one of unique columns, which must never be from user input.
+// email and username are compared in lower case to use their unique indexes.
+func (ur *UserRepository) findUser(ctx context.Context, condition, value string) (realworld.User, error) {
+	var user realworld.User
+	err := ur.pool.QueryRow(ctx,
+		`SELECT id, email, username, password, bio, image, token_version FROM users WHERE `+condition, value,
+	).Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.Bio, &user.Image, &user.TokenVersion)
+	if err != nil {
+		return realworld.User{}, err
+	}
+	return user, nil
+}
+
+// userAlreadyExists returns error on the column of users violating unique constraint named by PostgreSQL
+// like users_username_key
+func userAlreadyExists(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.ConstraintName == "users_username_key" || pgErr.ConstraintName == "users_username_lower") {
+		return realworld.ErrorUserAlreadyExists("username")
+	}
+	return realworld.ErrorUserAlreadyExists("email")
+}
@@ -0,0 +1,244 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/raeperd/realworld"
+)
+
+// ArticleRepository implements [realworld.ArticleRepository] and [realworld.TagRepository]
+type ArticleRepository struct {
+	db *sql.DB
+}
+
+func NewArticleRepository(db *sql.DB) *ArticleRepository {
+	return &ArticleRepository{db: db}
+}
+
+// selectArticle selects columns scanned by scanArticle, with tags as JSON array ordered by position
+const selectArticle = `SELECT a.id, a.slug, a.title, a.description, a.body, a.author_id, a.created_at, a.updated_at,
+	(SELECT json_group_array(tag) FROM (SELECT tag FROM article_tags WHERE article_id = a.id ORDER BY position)),
+	(SELECT COUNT(*) FROM favorites WHERE article_id = a.id)
+FROM articles a `
+
+func (ar *ArticleRepository) CreateArticle(ctx context.Context, article realworld.Article) (realworld.Article, error) {
+	err := inTx(ctx, ar.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO articles (slug, title, description, body, author_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+			article.Slug, article.Title, article.Description, article.Body, article.AuthorID, article.CreatedAt.UnixNano(), article.UpdatedAt.UnixNano(),
+		).Scan(&article.ID)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w with slug %s", realworld.ErrArticleAlreadyExists, article.Slug)
+		}
+		if err != nil {
+			return err
+		}
+		for i, tag := range article.TagList {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO article_tags (article_id, tag, position) VALUES (?, ?, ?)`, article.ID, tag, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return realworld.Article{}, err
+	}
+	return article, nil
+}
+
+func (ar *ArticleRepository) UpdateArticle(ctx context.Context, slug string, article realworld.Article) (realworld.Article, error) {
+	var updated realworld.Article
+	err := inTx(ctx, ar.db, func(tx *sql.Tx) error {
+		// NOTE: creation time, author and tags are fixed once created as in inmemory implementation
+		result, err := tx.ExecContext(ctx,
+			`UPDATE articles SET slug = ?, title = ?, description = ?, body = ?, updated_at = ? WHERE slug = ?`,
+			article.Slug, article.Title, article.Description, article.Body, article.UpdatedAt.UnixNano(), slug,
+		)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w with slug %s", realworld.ErrArticleAlreadyExists, article.Slug)
+		}
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			return errors.Join(err, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug))
+		}
+		updated, err = findArticleBySlug(ctx, tx, article.Slug)
+		return err
+	})
+	if err != nil {
+		return realworld.Article{}, err
+	}
+	return updated, nil
+}
+
+func (ar *ArticleRepository) DeleteArticle(ctx context.Context, slug string) error {
+	result, err := ar.db.ExecContext(ctx, `DELETE FROM articles WHERE slug = ?`, slug)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return errors.Join(err, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug))
+	}
+	return nil
+}
+
+func (ar *ArticleRepository) FindArticleBySlug(ctx context.Context, slug string) (realworld.Article, error) {
+	return findArticleBySlug(ctx, ar.db, slug)
+}
+
+func (ar *ArticleRepository) ListArticles(ctx context.Context, query realworld.ArticleQuery) ([]realworld.Article, int, error) {
+	where := `WHERE (? = '' OR a.author_id = ?)
+	AND (? = '' OR EXISTS (SELECT 1 FROM article_tags WHERE article_id = a.id AND tag = ?))
+	AND (? = '' OR EXISTS (SELECT 1 FROM favorites WHERE article_id = a.id AND user_id = ?)) `
+	args := []any{query.Author, query.Author, query.Tag, query.Tag, query.FavoritedBy, query.FavoritedBy}
+	return ar.listArticles(ctx, where, args, query.Page)
+}
+
+func (ar *ArticleRepository) ListFeed(ctx context.Context, query realworld.FeedQuery) ([]realworld.Article, int, error) {
+	if len(query.Authors) == 0 {
+		return []realworld.Article{}, 0, nil
+	}
+	in, args := inClause(query.Authors)
+	return ar.listArticles(ctx, `WHERE a.author_id IN `+in+` `, args, query.Page)
+}
+
+// listArticles returns a page of articles matching where clause newest first and total count of them
+func (ar *ArticleRepository) listArticles(ctx context.Context, where string, args []any, page realworld.Page) ([]realworld.Article, int, error) {
+	var count int
+	if err := ar.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM articles a `+where, args...).Scan(&count); err != nil {
+		return nil, 0, err
+	}
+	rows, err := ar.db.QueryContext(ctx, selectArticle+where+`ORDER BY a.created_at DESC, a.id DESC LIMIT ? OFFSET ?`,
+		append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	articles := make([]realworld.Article, 0, page.Limit)
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		articles = append(articles, article)
+	}
+	return articles, count, rows.Err()
+}
+
+func (ar *ArticleRepository) FavoriteArticle(ctx context.Context, slug, userID string) (realworld.Article, error) {
+	return ar.changeFavorite(ctx, slug, `INSERT OR IGNORE INTO favorites (article_id, user_id) VALUES (?, ?)`, userID)
+}
+
+func (ar *ArticleRepository) UnfavoriteArticle(ctx context.Context, slug, userID string) (realworld.Article, error) {
+	return ar.changeFavorite(ctx, slug, `DELETE FROM favorites WHERE article_id = ? AND user_id = ?`, userID)
+}
+
+// changeFavorite executes statement with article id and user id, returning article with updated favorites count
+func (ar *ArticleRepository) changeFavorite(ctx context.Context, slug, statement, userID string) (realworld.Article, error) {
+	var article realworld.Article
+	err := inTx(ctx, ar.db, func(tx *sql.Tx) error {
+		id, err := articleIDBySlug(ctx, tx, slug)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, statement, id, userID); err != nil {
+			return err
+		}
+		article, err = findArticleBySlug(ctx, tx, slug)
+		return err
+	})
+	if err != nil {
+		return realworld.Article{}, err
+	}
+	return article, nil
+}
+
+func (ar *ArticleRepository) IsFavorited(ctx context.Context, slug, userID string) (bool, error) {
+	id, err := articleIDBySlug(ctx, ar.db, slug)
+	if err != nil {
+		return false, err
+	}
+	var favorited bool
+	err = ar.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM favorites WHERE article_id = ? AND user_id = ?)`, id, userID,
+	).Scan(&favorited)
+	return favorited, err
+}
+
+func (ar *ArticleRepository) FavoritedAmong(ctx context.Context, userID string, ids []int64) ([]int64, error) {
+	favorited := make([]int64, 0, len(ids))
+	if len(ids) == 0 {
+		return favorited, nil
+	}
+	in, args := inClause(ids)
+	rows, err := ar.db.QueryContext(ctx, `SELECT article_id FROM favorites WHERE user_id = ? AND article_id IN `+in, append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		favorited = append(favorited, id)
+	}
+	return favorited, rows.Err()
+}
+
+func (ar *ArticleRepository) ListTags(ctx context.Context) ([]string, error) {
+	rows, err := ar.db.QueryContext(ctx, `SELECT DISTINCT tag FROM article_tags ORDER BY tag`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func articleIDBySlug(ctx context.Context, q querier, slug string) (int64, error) {
+	var id int64
+	err := q.QueryRowContext(ctx, `SELECT id FROM articles WHERE slug = ?`, slug).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	return id, err
+}
+
+func findArticleBySlug(ctx context.Context, q querier, slug string) (realworld.Article, error) {
+	article, err := scanArticle(q.QueryRowContext(ctx, selectArticle+`WHERE a.slug = ?`, slug))
+	if errors.Is(err, sql.ErrNoRows) {
+		return realworld.Article{}, fmt.Errorf("%w with slug %s", realworld.ErrArticleNotFound, slug)
+	}
+	return article, err
+}
+
+func scanArticle(row interface{ Scan(...any) error }) (realworld.Article, error) {
+	var article realworld.Article
+	var createdAt, updatedAt int64
+	var tags string
+	err := row.Scan(&article.ID, &article.Slug, &article.Title, &article.Description, &article.Body, &article.AuthorID,
+		&createdAt, &updatedAt, &tags, &article.FavoritesCount)
+	if err != nil {
+		return realworld.Article{}, err
+	}
+	if err := json.Unmarshal([]byte(tags), &article.TagList); err != nil {
+		return realworld.Article{}, fmt.Errorf("decode tags of article %s: %w", article.Slug, err)
+	}
+	article.CreatedAt = time.Unix(0, createdAt)
+	article.UpdatedAt = time.Unix(0, updatedAt)
+	return article, nil
+}
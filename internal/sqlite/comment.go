@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/raeperd/realworld"
+)
+
+// CommentRepository implements [realworld.CommentRepository]
+type CommentRepository struct {
+	db *sql.DB
+}
+
+func NewCommentRepository(db *sql.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+const selectComment = `SELECT id, article_id, body, author_id, created_at, updated_at FROM comments `
+
+func (cr *CommentRepository) CreateComment(ctx context.Context, comment realworld.Comment) (realworld.Comment, error) {
+	err := cr.db.QueryRowContext(ctx,
+		`INSERT INTO comments (article_id, body, author_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?) RETURNING id`,
+		comment.ArticleID, comment.Body, comment.AuthorID, comment.CreatedAt.UnixNano(), comment.UpdatedAt.UnixNano(),
+	).Scan(&comment.ID)
+	if err != nil {
+		return realworld.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (cr *CommentRepository) FindCommentByID(ctx context.Context, id int64) (realworld.Comment, error) {
+	comment, err := scanComment(cr.db.QueryRowContext(ctx, selectComment+`WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return realworld.Comment{}, fmt.Errorf("%w with id %d", realworld.ErrCommentNotFound, id)
+	}
+	return comment, err
+}
+
+func (cr *CommentRepository) ListComments(ctx context.Context, articleID int64) ([]realworld.Comment, error) {
+	rows, err := cr.db.QueryContext(ctx, selectComment+`WHERE article_id = ? ORDER BY id`, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	comments := []realworld.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (cr *CommentRepository) DeleteComment(ctx context.Context, id int64) error {
+	result, err := cr.db.ExecContext(ctx, `DELETE FROM comments WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return errors.Join(err, fmt.Errorf("%w with id %d", realworld.ErrCommentNotFound, id))
+	}
+	return nil
+}
+
+func scanComment(row interface{ Scan(...any) error }) (realworld.Comment, error) {
+	var comment realworld.Comment
+	var createdAt, updatedAt int64
+	if err := row.Scan(&comment.ID, &comment.ArticleID, &comment.Body, &comment.AuthorID, &createdAt, &updatedAt); err != nil {
+		return realworld.Comment{}, err
+	}
+	comment.CreatedAt = time.Unix(0, createdAt)
+	comment.UpdatedAt = time.Unix(0, updatedAt)
+	return comment, nil
+}
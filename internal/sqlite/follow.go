@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+)
+
+// FollowRepository implements [realworld.FollowRepository]
+type FollowRepository struct {
+	db *sql.DB
+}
+
+func NewFollowRepository(db *sql.DB) *FollowRepository {
+	return &FollowRepository{db: db}
+}
+
+func (fr *FollowRepository) Follow(ctx context.Context, follower, followee string) error {
+	_, err := fr.db.ExecContext(ctx, `INSERT OR IGNORE INTO follows (follower_id, followee_id) VALUES (?, ?)`, follower, followee)
+	return err
+}
+
+func (fr *FollowRepository) Unfollow(ctx context.Context, follower, followee string) error {
+	_, err := fr.db.ExecContext(ctx, `DELETE FROM follows WHERE follower_id = ? AND followee_id = ?`, follower, followee)
+	return err
+}
+
+func (fr *FollowRepository) IsFollowing(ctx context.Context, follower, followee string) (bool, error) {
+	var following bool
+	err := fr.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = ? AND followee_id = ?)`, follower, followee,
+	).Scan(&following)
+	return following, err
+}
+
+func (fr *FollowRepository) Followees(ctx context.Context, follower string) ([]string, error) {
+	rows, err := fr.db.QueryContext(ctx, `SELECT followee_id FROM follows WHERE follower_id = ?`, follower)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	followees := []string{}
+	for rows.Next() {
+		var followee string
+		if err := rows.Scan(&followee); err != nil {
+			return nil, err
+		}
+		followees = append(followees, followee)
+	}
+	return followees, rows.Err()
+}
+
+func (fr *FollowRepository) FolloweesAmong(ctx context.Context, follower string, ids []string) ([]string, error) {
+	followees := make([]string, 0, len(ids))
+	if len(ids) == 0 {
+		return followees, nil
+	}
+	in, args := inClause(ids)
+	rows, err := fr.db.QueryContext(ctx, `SELECT followee_id FROM follows WHERE follower_id = ? AND followee_id IN `+in, append([]any{follower}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var followee string
+		if err := rows.Scan(&followee); err != nil {
+			return nil, err
+		}
+		followees = append(followees, followee)
+	}
+	return followees, rows.Err()
+}
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/raeperd/realworld/internal/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// NewMigrator returns migrator of schema embedded in migrations directory
+func NewMigrator(db *sql.DB) (migrate.Migrator, error) {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return migrate.Migrator{}, err
+	}
+	return migrate.NewMigrator(migrationDriver{db: db}, sub)
+}
+
+// migrationDriver implements [migrate.Driver]
+type migrationDriver struct {
+	db *sql.DB
+}
+
+const (
+	// migrationLockTTL is how long lock is held at most, after which it is taken as left by runner crashed while migrating
+	migrationLockTTL = 10 * time.Minute
+	// migrationLockRetry is interval of trying to take lock held by other runner
+	migrationLockRetry = 100 * time.Millisecond
+)
+
+// Lock takes the only row of schema_migrations_lock as SQLite has no advisory lock,
+// waiting until other runner sharing the database file deletes it on unlock.
+// Lock is taken by statements of their own, so that single connection of [Open] is left for Versions and Apply.
+func (d migrationDriver) Lock(ctx context.Context) (func() error, error) {
+	_, err := d.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+		id        INTEGER PRIMARY KEY CHECK (id = 1),
+		locked_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		now := time.Now()
+		result, err := d.db.ExecContext(ctx, `INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)
+			ON CONFLICT (id) DO UPDATE SET locked_at = excluded.locked_at WHERE locked_at < ?`,
+			now.UnixNano(), now.Add(-migrationLockTTL).UnixNano(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if n == 1 {
+			return func() error {
+				// NOTE: locked_at identifies the lock, so that lock taken over after expired is left to its new holder
+				_, err := d.db.ExecContext(context.Background(), `DELETE FROM schema_migrations_lock WHERE locked_at = ?`, now.UnixNano())
+				return err
+			}, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(migrationLockRetry):
+		}
+	}
+}
+
+func (d migrationDriver) Versions(ctx context.Context) ([]int64, error) {
+	_, err := d.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.db.QueryContext(ctx, `SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	versions := []int64{}
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+func (d migrationDriver) Apply(ctx context.Context, migration migrate.Migration, up bool) error {
+	return inTx(ctx, d.db, func(tx *sql.Tx) error {
+		var applied bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)`, migration.Version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if up && applied {
+			return fmt.Errorf("version %d is already applied", migration.Version)
+		}
+		if !up && !applied {
+			return fmt.Errorf("version %d is not applied", migration.Version)
+		}
+		if up {
+			if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, migration.Version, time.Now().UnixNano())
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, migration.Version)
+		return err
+	})
+}
@@ -0,0 +1,72 @@
+CREATE TABLE users (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    email         TEXT    NOT NULL UNIQUE,
+    username      TEXT    NOT NULL UNIQUE,
+    password      TEXT    NOT NULL,
+    bio           TEXT    NOT NULL DEFAULT '',
+    image         TEXT    NOT NULL DEFAULT '',
+    token_version INTEGER NOT NULL DEFAULT 0
+);
+
+-- NOTE: users are referenced by email as in the domain, which cascades on update of email
+CREATE TABLE follows (
+    follower TEXT NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    followee TEXT NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (follower, followee)
+);
+
+CREATE TABLE articles (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug         TEXT    NOT NULL UNIQUE,
+    title        TEXT    NOT NULL,
+    description  TEXT    NOT NULL,
+    body         TEXT    NOT NULL,
+    author_email TEXT    NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    -- created_at and updated_at are unix nanoseconds
+    created_at   INTEGER NOT NULL,
+    updated_at   INTEGER NOT NULL
+);
+CREATE INDEX articles_created_at ON articles (created_at);
+CREATE INDEX articles_author_email ON articles (author_email, created_at);
+
+CREATE TABLE article_tags (
+    article_id INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    tag        TEXT    NOT NULL,
+    -- position keeps order of tag list
+    position   INTEGER NOT NULL,
+    PRIMARY KEY (article_id, tag)
+);
+CREATE INDEX article_tags_tag ON article_tags (tag);
+
+CREATE TABLE favorites (
+    article_id INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    email      TEXT    NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (article_id, email)
+);
+CREATE INDEX favorites_email ON favorites (email);
+
+CREATE TABLE comments (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id   INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    body         TEXT    NOT NULL,
+    author_email TEXT    NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at   INTEGER NOT NULL,
+    updated_at   INTEGER NOT NULL
+);
+CREATE INDEX comments_article_id ON comments (article_id);
+
+CREATE TABLE refresh_tokens (
+    hash          TEXT    PRIMARY KEY,
+    family        TEXT    NOT NULL,
+    email         TEXT    NOT NULL REFERENCES users (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    expires_at    INTEGER NOT NULL,
+    token_version INTEGER NOT NULL,
+    -- uses counts rotations, so that more than one means reuse
+    uses          INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX refresh_tokens_family ON refresh_tokens (family);
+
+CREATE TABLE revoked_tokens (
+    jti        TEXT    PRIMARY KEY,
+    expires_at INTEGER NOT NULL
+);
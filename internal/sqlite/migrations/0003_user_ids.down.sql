@@ -0,0 +1,113 @@
+-- NOTE: users are given integer ids again in order of their ids, and referenced by email
+CREATE TABLE users_old (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    email         TEXT    NOT NULL UNIQUE,
+    username      TEXT    NOT NULL UNIQUE,
+    password      TEXT    NOT NULL,
+    bio           TEXT    NOT NULL DEFAULT '',
+    image         TEXT    NOT NULL DEFAULT '',
+    token_version INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO users_old (email, username, password, bio, image, token_version)
+SELECT email, username, password, bio, image, token_version FROM users ORDER BY id;
+
+CREATE TABLE follows_old (
+    follower TEXT NOT NULL REFERENCES users_old (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    followee TEXT NOT NULL REFERENCES users_old (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (follower, followee)
+);
+INSERT INTO follows_old (follower, followee)
+SELECT follower.email, followee.email
+FROM follows
+JOIN users follower ON follower.id = follows.follower_id
+JOIN users followee ON followee.id = follows.followee_id;
+
+CREATE TABLE articles_old (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug         TEXT    NOT NULL UNIQUE,
+    title        TEXT    NOT NULL,
+    description  TEXT    NOT NULL,
+    body         TEXT    NOT NULL,
+    author_email TEXT    NOT NULL REFERENCES users_old (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    -- created_at and updated_at are unix nanoseconds
+    created_at   INTEGER NOT NULL,
+    updated_at   INTEGER NOT NULL
+);
+INSERT INTO articles_old (id, slug, title, description, body, author_email, created_at, updated_at)
+SELECT articles.id, slug, title, description, body, users.email, created_at, updated_at
+FROM articles
+JOIN users ON users.id = articles.author_id;
+
+CREATE TABLE article_tags_old (
+    article_id INTEGER NOT NULL REFERENCES articles_old (id) ON DELETE CASCADE,
+    tag        TEXT    NOT NULL,
+    -- position keeps order of tag list
+    position   INTEGER NOT NULL,
+    PRIMARY KEY (article_id, tag)
+);
+INSERT INTO article_tags_old (article_id, tag, position)
+SELECT article_id, tag, position FROM article_tags;
+
+CREATE TABLE favorites_old (
+    article_id INTEGER NOT NULL REFERENCES articles_old (id) ON DELETE CASCADE,
+    email      TEXT    NOT NULL REFERENCES users_old (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (article_id, email)
+);
+INSERT INTO favorites_old (article_id, email)
+SELECT favorites.article_id, users.email
+FROM favorites
+JOIN users ON users.id = favorites.user_id;
+
+CREATE TABLE comments_old (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id   INTEGER NOT NULL REFERENCES articles_old (id) ON DELETE CASCADE,
+    body         TEXT    NOT NULL,
+    author_email TEXT    NOT NULL REFERENCES users_old (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at   INTEGER NOT NULL,
+    updated_at   INTEGER NOT NULL
+);
+INSERT INTO comments_old (id, article_id, body, author_email, created_at, updated_at)
+SELECT comments.id, article_id, body, users.email, created_at, updated_at
+FROM comments
+JOIN users ON users.id = comments.author_id;
+
+CREATE TABLE refresh_tokens_old (
+    hash          TEXT    PRIMARY KEY,
+    family        TEXT    NOT NULL,
+    email         TEXT    NOT NULL REFERENCES users_old (email) ON UPDATE CASCADE ON DELETE CASCADE,
+    expires_at    INTEGER NOT NULL,
+    token_version INTEGER NOT NULL,
+    -- uses counts rotations, so that more than one means reuse
+    uses          INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO refresh_tokens_old (hash, family, email, expires_at, token_version, uses)
+SELECT hash, family, users.email, expires_at, refresh_tokens.token_version, uses
+FROM refresh_tokens
+JOIN users ON users.id = refresh_tokens.user_id;
+
+DROP TABLE refresh_tokens;
+DROP TABLE comments;
+DROP TABLE favorites;
+DROP TABLE article_tags;
+DROP TABLE follows;
+DROP TABLE articles;
+DROP TABLE users;
+
+ALTER TABLE users_old RENAME TO users;
+ALTER TABLE follows_old RENAME TO follows;
+ALTER TABLE articles_old RENAME TO articles;
+ALTER TABLE article_tags_old RENAME TO article_tags;
+ALTER TABLE favorites_old RENAME TO favorites;
+ALTER TABLE comments_old RENAME TO comments;
+ALTER TABLE refresh_tokens_old RENAME TO refresh_tokens;
+
+CREATE INDEX articles_created_at ON articles (created_at);
+CREATE INDEX articles_author_email ON articles (author_email, created_at);
+CREATE INDEX article_tags_tag ON article_tags (tag);
+CREATE INDEX favorites_email ON favorites (email);
+CREATE INDEX comments_article_id ON comments (article_id);
+CREATE INDEX refresh_tokens_family ON refresh_tokens (family);
+
+-- NOTE: case-insensitive indexes of 0002_case_insensitive_users are dropped along with users, so they are created again
+CREATE UNIQUE INDEX users_email_lower ON users (lower(email));
+CREATE UNIQUE INDEX users_username_lower ON users (lower(username));
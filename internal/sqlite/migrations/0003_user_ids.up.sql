@@ -0,0 +1,119 @@
+-- NOTE: every table referencing users is rebuilt to reference users by id, as SQLite cannot alter foreign keys.
+-- Existing users are given random UUIDv4 while users created from now on get UUIDv7 from application.
+CREATE TABLE users_new (
+    id            TEXT    PRIMARY KEY,
+    email         TEXT    NOT NULL UNIQUE,
+    username      TEXT    NOT NULL UNIQUE,
+    password      TEXT    NOT NULL,
+    bio           TEXT    NOT NULL DEFAULT '',
+    image         TEXT    NOT NULL DEFAULT '',
+    token_version INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO users_new (id, email, username, password, bio, image, token_version)
+SELECT lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' ||
+             substr('89ab', 1 + abs(random()) % 4, 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))),
+       email, username, password, bio, image, token_version
+FROM users;
+
+CREATE TABLE follows_new (
+    follower_id TEXT NOT NULL REFERENCES users_new (id) ON DELETE CASCADE,
+    followee_id TEXT NOT NULL REFERENCES users_new (id) ON DELETE CASCADE,
+    PRIMARY KEY (follower_id, followee_id)
+);
+INSERT INTO follows_new (follower_id, followee_id)
+SELECT follower.id, followee.id
+FROM follows
+JOIN users_new follower ON follower.email = follows.follower
+JOIN users_new followee ON followee.email = follows.followee;
+
+CREATE TABLE articles_new (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug        TEXT    NOT NULL UNIQUE,
+    title       TEXT    NOT NULL,
+    description TEXT    NOT NULL,
+    body        TEXT    NOT NULL,
+    author_id   TEXT    NOT NULL REFERENCES users_new (id) ON DELETE CASCADE,
+    -- created_at and updated_at are unix nanoseconds
+    created_at  INTEGER NOT NULL,
+    updated_at  INTEGER NOT NULL
+);
+INSERT INTO articles_new (id, slug, title, description, body, author_id, created_at, updated_at)
+SELECT articles.id, slug, title, description, body, users_new.id, created_at, updated_at
+FROM articles
+JOIN users_new ON users_new.email = articles.author_email;
+
+CREATE TABLE article_tags_new (
+    article_id INTEGER NOT NULL REFERENCES articles_new (id) ON DELETE CASCADE,
+    tag        TEXT    NOT NULL,
+    -- position keeps order of tag list
+    position   INTEGER NOT NULL,
+    PRIMARY KEY (article_id, tag)
+);
+INSERT INTO article_tags_new (article_id, tag, position)
+SELECT article_id, tag, position FROM article_tags;
+
+CREATE TABLE favorites_new (
+    article_id INTEGER NOT NULL REFERENCES articles_new (id) ON DELETE CASCADE,
+    user_id    TEXT    NOT NULL REFERENCES users_new (id) ON DELETE CASCADE,
+    PRIMARY KEY (article_id, user_id)
+);
+INSERT INTO favorites_new (article_id, user_id)
+SELECT favorites.article_id, users_new.id
+FROM favorites
+JOIN users_new ON users_new.email = favorites.email;
+
+CREATE TABLE comments_new (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id INTEGER NOT NULL REFERENCES articles_new (id) ON DELETE CASCADE,
+    body       TEXT    NOT NULL,
+    author_id  TEXT    NOT NULL REFERENCES users_new (id) ON DELETE CASCADE,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+INSERT INTO comments_new (id, article_id, body, author_id, created_at, updated_at)
+SELECT comments.id, article_id, body, users_new.id, created_at, updated_at
+FROM comments
+JOIN users_new ON users_new.email = comments.author_email;
+
+CREATE TABLE refresh_tokens_new (
+    hash          TEXT    PRIMARY KEY,
+    family        TEXT    NOT NULL,
+    user_id       TEXT    NOT NULL REFERENCES users_new (id) ON DELETE CASCADE,
+    expires_at    INTEGER NOT NULL,
+    token_version INTEGER NOT NULL,
+    -- uses counts rotations, so that more than one means reuse
+    uses          INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO refresh_tokens_new (hash, family, user_id, expires_at, token_version, uses)
+SELECT hash, family, users_new.id, expires_at, refresh_tokens.token_version, uses
+FROM refresh_tokens
+JOIN users_new ON users_new.email = refresh_tokens.email;
+
+-- NOTE: referencing tables are dropped first so that dropping never cascades into tables rebuilt
+DROP TABLE refresh_tokens;
+DROP TABLE comments;
+DROP TABLE favorites;
+DROP TABLE article_tags;
+DROP TABLE follows;
+DROP TABLE articles;
+DROP TABLE users;
+
+-- NOTE: renaming rewrites references to the renamed table in foreign keys of other tables
+ALTER TABLE users_new RENAME TO users;
+ALTER TABLE follows_new RENAME TO follows;
+ALTER TABLE articles_new RENAME TO articles;
+ALTER TABLE article_tags_new RENAME TO article_tags;
+ALTER TABLE favorites_new RENAME TO favorites;
+ALTER TABLE comments_new RENAME TO comments;
+ALTER TABLE refresh_tokens_new RENAME TO refresh_tokens;
+
+CREATE INDEX articles_created_at ON articles (created_at);
+CREATE INDEX articles_author_id ON articles (author_id, created_at);
+CREATE INDEX article_tags_tag ON article_tags (tag);
+CREATE INDEX favorites_user_id ON favorites (user_id);
+CREATE INDEX comments_article_id ON comments (article_id);
+CREATE INDEX refresh_tokens_family ON refresh_tokens (family);
+
+-- NOTE: case-insensitive indexes of 0002_case_insensitive_users are dropped along with users, so they are created again
+CREATE UNIQUE INDEX users_email_lower ON users (lower(email));
+CREATE UNIQUE INDEX users_username_lower ON users (lower(username));
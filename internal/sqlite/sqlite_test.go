@@ -0,0 +1,377 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/carlmjohnson/be"
+	"github.com/raeperd/realworld"
+	"github.com/raeperd/realworld/internal/migrate"
+	"github.com/raeperd/realworld/internal/sqlite"
+)
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := sqlite.NewUserRepository(openDB(t))
+
+	created, err := repo.CreateUser(ctx, newUser("alice"))
+	be.NilErr(t, err)
+	be.Nonzero(t, created.ID)
+
+	var fieldErr realworld.FieldError
+	duplicatedEmail := newUser("bob")
+	duplicatedEmail.Email = created.Email
+	_, err = repo.CreateUser(ctx, duplicatedEmail)
+	be.True(t, errors.Is(err, realworld.ErrUserAlreadyExists))
+	be.True(t, errors.As(err, &fieldErr))
+	be.Equal(t, "email", fieldErr.Field)
+	duplicatedName := newUser("alice")
+	duplicatedName.ID = "other-id"
+	duplicatedName.Email = "other@email.com"
+	_, err = repo.CreateUser(ctx, duplicatedName)
+	be.True(t, errors.As(err, &fieldErr))
+	be.Equal(t, "username", fieldErr.Field)
+
+	// NOTE: email and username differing only in case are taken as the same like in-memory repository
+	duplicatedCase := newUser("carol")
+	duplicatedCase.Email = "ALICE@email.com"
+	_, err = repo.CreateUser(ctx, duplicatedCase)
+	be.True(t, errors.As(err, &fieldErr))
+	be.Equal(t, "email", fieldErr.Field)
+	duplicatedCase = newUser("Alice")
+	duplicatedCase.ID = "other-case-id"
+	duplicatedCase.Email = "other-case@email.com"
+	_, err = repo.CreateUser(ctx, duplicatedCase)
+	be.True(t, errors.As(err, &fieldErr))
+	be.Equal(t, "username", fieldErr.Field)
+
+	found, err := repo.FindUserByUsername(ctx, "ALICE")
+	be.NilErr(t, err)
+	be.Equal(t, created, found)
+	found, err = repo.FindUserByEmail(ctx, "Alice@Email.com")
+	be.NilErr(t, err)
+	be.Equal(t, created, found)
+
+	updated := created
+	updated.Email = "alice@new.com"
+	updated.Bio = "bio"
+	updated, err = repo.UpdateUser(ctx, updated)
+	be.NilErr(t, err)
+	be.Equal(t, created.ID, updated.ID)
+	found, err = repo.FindUserByEmail(ctx, "alice@new.com")
+	be.NilErr(t, err)
+	be.Equal(t, updated, found)
+	found, err = repo.FindUserByID(ctx, created.ID)
+	be.NilErr(t, err)
+	be.Equal(t, updated, found)
+	_, err = repo.UpdateUser(ctx, newUser("missing"))
+	be.True(t, errors.Is(err, realworld.ErrUserNotFound))
+
+	// NOTE: update with stale token version must not restore tokens revoked meanwhile
+	be.NilErr(t, repo.IncrementTokenVersion(ctx, created.ID))
+	updated, err = repo.UpdateUser(ctx, updated)
+	be.NilErr(t, err)
+	be.Equal(t, created.TokenVersion+1, updated.TokenVersion)
+	found, err = repo.FindUserByID(ctx, created.ID)
+	be.NilErr(t, err)
+	be.Equal(t, created.TokenVersion+1, found.TokenVersion)
+	be.True(t, errors.Is(repo.IncrementTokenVersion(ctx, "missing"), realworld.ErrUserNotFound))
+
+	_, err = repo.FindUserByEmail(ctx, created.Email)
+	be.True(t, errors.Is(err, realworld.ErrUserNotFound))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = repo.FindUserByEmail(canceled, "alice@new.com")
+	be.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestArticleRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	users := sqlite.NewUserRepository(db)
+	repo := sqlite.NewArticleRepository(db)
+	author, err := users.CreateUser(ctx, newUser("author"))
+	be.NilErr(t, err)
+	reader, err := users.CreateUser(ctx, newUser("reader"))
+	be.NilErr(t, err)
+
+	now := time.Now()
+	for i, slug := range []string{"first", "second", "third"} {
+		_, err := repo.CreateArticle(ctx, realworld.Article{
+			Slug: slug, Title: slug, TagList: []string{"b", slug, "a"}, AuthorID: author.ID,
+			CreatedAt: now.Add(time.Duration(i) * time.Second), UpdatedAt: now,
+		})
+		be.NilErr(t, err)
+	}
+	_, err = repo.CreateArticle(ctx, realworld.Article{Slug: "first", AuthorID: author.ID})
+	be.True(t, errors.Is(err, realworld.ErrArticleAlreadyExists))
+
+	article, err := repo.FindArticleBySlug(ctx, "first")
+	be.NilErr(t, err)
+	be.AllEqual(t, []string{"b", "first", "a"}, article.TagList)
+	be.True(t, article.CreatedAt.Equal(now))
+
+	article, err = repo.FavoriteArticle(ctx, "first", reader.ID)
+	be.NilErr(t, err)
+	be.Equal(t, 1, article.FavoritesCount)
+	favorited, err := repo.IsFavorited(ctx, "first", reader.ID)
+	be.NilErr(t, err)
+	be.True(t, favorited)
+	_, err = repo.FavoriteArticle(ctx, "missing", reader.ID)
+	be.True(t, errors.Is(err, realworld.ErrArticleNotFound))
+	second, err := repo.FindArticleBySlug(ctx, "second")
+	be.NilErr(t, err)
+	ids, err := repo.FavoritedAmong(ctx, reader.ID, []int64{article.ID, second.ID})
+	be.NilErr(t, err)
+	be.AllEqual(t, []int64{article.ID}, ids)
+
+	found, err := users.FindUsersByIDs(ctx, []string{author.ID, "missing"})
+	be.NilErr(t, err)
+	be.Equal(t, 1, len(found))
+	be.Equal(t, author, found[0])
+
+	articles, count, err := repo.ListArticles(ctx, realworld.ArticleQuery{Tag: "a", Page: realworld.Page{Limit: 2}})
+	be.NilErr(t, err)
+	be.Equal(t, 3, count)
+	be.Equal(t, 2, len(articles))
+	be.Equal(t, "third", articles[0].Slug)
+
+	articles, count, err = repo.ListArticles(ctx, realworld.ArticleQuery{FavoritedBy: reader.ID, Page: realworld.Page{Limit: 20}})
+	be.NilErr(t, err)
+	be.Equal(t, 1, count)
+	be.Equal(t, "first", articles[0].Slug)
+
+	articles, count, err = repo.ListFeed(ctx, realworld.FeedQuery{Authors: []string{author.ID}, Page: realworld.Page{Limit: 20, Offset: 1}})
+	be.NilErr(t, err)
+	be.Equal(t, 3, count)
+	be.Equal(t, 2, len(articles))
+
+	tags, err := repo.ListTags(ctx)
+	be.NilErr(t, err)
+	be.AllEqual(t, []string{"a", "b", "first", "second", "third"}, tags)
+
+	be.NilErr(t, repo.DeleteArticle(ctx, "first"))
+	be.True(t, errors.Is(repo.DeleteArticle(ctx, "first"), realworld.ErrArticleNotFound))
+}
+
+func TestRefreshTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	user, err := sqlite.NewUserRepository(db).CreateUser(ctx, newUser("user"))
+	be.NilErr(t, err)
+	repo := sqlite.NewRefreshTokenRepository(db)
+
+	token := realworld.RefreshToken{Hash: "hash", Family: "family", UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	be.NilErr(t, repo.CreateRefreshToken(ctx, token))
+
+	used, err := repo.UseRefreshToken(ctx, "hash")
+	be.NilErr(t, err)
+	be.False(t, used.Used)
+	used, err = repo.UseRefreshToken(ctx, "hash")
+	be.NilErr(t, err)
+	be.True(t, used.Used)
+
+	be.NilErr(t, repo.RevokeTokenFamily(ctx, "family"))
+	_, err = repo.UseRefreshToken(ctx, "hash")
+	be.True(t, errors.Is(err, realworld.ErrInvalidRefreshToken))
+}
+
+func TestRevokedTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := sqlite.NewRevokedTokenRepository(openDB(t), func() time.Time { return now })
+
+	be.NilErr(t, repo.RevokeToken(ctx, "expiring", now.Add(time.Minute)))
+	be.NilErr(t, repo.RevokeToken(ctx, "shortened", now.Add(time.Hour)))
+	revoked, err := repo.IsTokenRevoked(ctx, "shortened")
+	be.NilErr(t, err)
+	be.True(t, revoked)
+
+	now = now.Add(30 * time.Minute)
+	be.NilErr(t, repo.RevokeToken(ctx, "shortened", now.Add(time.Minute)))
+	now = now.Add(time.Hour)
+	be.NilErr(t, repo.RevokeToken(ctx, "another", now.Add(time.Hour)))
+	for jti, want := range map[string]bool{"expiring": false, "shortened": false, "another": true} {
+		revoked, err := repo.IsTokenRevoked(ctx, jti)
+		be.NilErr(t, err)
+		be.Equal(t, want, revoked)
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "realworld.db"))
+	be.NilErr(t, err)
+	t.Cleanup(func() { db.Close() })
+	migrator, err := sqlite.NewMigrator(db)
+	be.NilErr(t, err)
+
+	statuses, err := migrator.Status(ctx)
+	be.NilErr(t, err)
+	be.Nonzero(t, len(statuses))
+	for _, status := range statuses {
+		be.False(t, status.Applied)
+	}
+
+	migrated, err := migrator.Up(ctx)
+	be.NilErr(t, err)
+	be.Equal(t, len(statuses), len(migrated))
+	migrated, err = migrator.Up(ctx)
+	be.NilErr(t, err)
+	be.Equal(t, 0, len(migrated))
+	_, err = sqlite.NewUserRepository(db).CreateUser(ctx, newUser("user"))
+	be.NilErr(t, err)
+
+	for range statuses {
+		_, err := migrator.Down(ctx)
+		be.NilErr(t, err)
+	}
+	_, err = migrator.Down(ctx)
+	be.True(t, errors.Is(err, migrate.ErrNoApplied))
+	_, err = sqlite.NewUserRepository(db).CreateUser(ctx, newUser("user"))
+	be.Nonzero(t, err)
+}
+
+// TestMigrateUserIDs migrates data stored when users are referenced by email to user ids and back
+func TestMigrateUserIDs(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	migrator, err := sqlite.NewMigrator(db)
+	be.NilErr(t, err)
+	migrateDownTo(t, migrator, 2)
+	for _, statement := range []string{
+		`INSERT INTO users (email, username, password) VALUES ('alice@email.com', 'alice', 'hash'), ('bob@email.com', 'bob', 'hash')`,
+		`INSERT INTO follows (follower, followee) VALUES ('alice@email.com', 'bob@email.com')`,
+		`INSERT INTO articles (slug, title, description, body, author_email, created_at, updated_at) VALUES ('slug', 'title', 'description', 'body', 'bob@email.com', 1, 1)`,
+		`INSERT INTO article_tags (article_id, tag, position) VALUES (1, 'tag', 0)`,
+		`INSERT INTO favorites (article_id, email) VALUES (1, 'alice@email.com')`,
+		`INSERT INTO comments (article_id, body, author_email, created_at, updated_at) VALUES (1, 'comment', 'alice@email.com', 1, 1)`,
+		`INSERT INTO refresh_tokens (hash, family, email, expires_at, token_version) VALUES ('hash', 'family', 'alice@email.com', 1, 0)`,
+	} {
+		_, err := db.ExecContext(ctx, statement)
+		be.NilErr(t, err)
+	}
+
+	_, err = migrator.Up(ctx)
+	be.NilErr(t, err)
+	users := sqlite.NewUserRepository(db)
+	alice, err := users.FindUserByEmail(ctx, "alice@email.com")
+	be.NilErr(t, err)
+	bob, err := users.FindUserByEmail(ctx, "bob@email.com")
+	be.NilErr(t, err)
+	be.Unequal(t, alice.ID, bob.ID)
+	// NOTE: users table migrated keeps the case-insensitive unique indexes of migration before
+	_, err = users.CreateUser(ctx, newUser("ALICE"))
+	be.True(t, errors.Is(err, realworld.ErrUserAlreadyExists))
+	following, err := sqlite.NewFollowRepository(db).IsFollowing(ctx, alice.ID, bob.ID)
+	be.NilErr(t, err)
+	be.True(t, following)
+	articles := sqlite.NewArticleRepository(db)
+	article, err := articles.FindArticleBySlug(ctx, "slug")
+	be.NilErr(t, err)
+	be.Equal(t, bob.ID, article.AuthorID)
+	be.AllEqual(t, []string{"tag"}, article.TagList)
+	be.Equal(t, 1, article.FavoritesCount)
+	favorited, err := articles.IsFavorited(ctx, "slug", alice.ID)
+	be.NilErr(t, err)
+	be.True(t, favorited)
+	comments, err := sqlite.NewCommentRepository(db).ListComments(ctx, article.ID)
+	be.NilErr(t, err)
+	be.Equal(t, 1, len(comments))
+	be.Equal(t, alice.ID, comments[0].AuthorID)
+	token, err := sqlite.NewRefreshTokenRepository(db).UseRefreshToken(ctx, "hash")
+	be.NilErr(t, err)
+	be.Equal(t, alice.ID, token.UserID)
+
+	migrateDownTo(t, migrator, 2)
+	for _, query := range []string{
+		`SELECT count(*) FROM follows WHERE follower = 'alice@email.com' AND followee = 'bob@email.com'`,
+		`SELECT count(*) FROM articles WHERE slug = 'slug' AND author_email = 'bob@email.com'`,
+		`SELECT count(*) FROM article_tags JOIN articles ON articles.id = article_id WHERE slug = 'slug' AND tag = 'tag'`,
+		`SELECT count(*) FROM favorites JOIN articles ON articles.id = article_id WHERE slug = 'slug' AND email = 'alice@email.com'`,
+		`SELECT count(*) FROM comments JOIN articles ON articles.id = article_id WHERE slug = 'slug' AND comments.author_email = 'alice@email.com'`,
+		`SELECT count(*) FROM refresh_tokens WHERE hash = 'hash' AND email = 'alice@email.com'`,
+	} {
+		var count int
+		be.NilErr(t, db.QueryRowContext(ctx, query).Scan(&count))
+		be.Equal(t, 1, count)
+	}
+}
+
+// TestMigratorConcurrent runs migrators of replicas sharing the database file at once
+func TestMigratorConcurrent(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "realworld.db")
+	errs := make(chan error)
+	counts := make(chan int, 2)
+	for range 2 {
+		go func() {
+			db, err := sqlite.Open(ctx, file)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer db.Close()
+			migrator, err := sqlite.NewMigrator(db)
+			if err != nil {
+				errs <- err
+				return
+			}
+			migrated, err := migrator.Up(ctx)
+			counts <- len(migrated)
+			errs <- err
+		}()
+	}
+	be.NilErr(t, <-errs)
+	be.NilErr(t, <-errs)
+
+	db, err := sqlite.Open(ctx, file)
+	be.NilErr(t, err)
+	t.Cleanup(func() { db.Close() })
+	migrator, err := sqlite.NewMigrator(db)
+	be.NilErr(t, err)
+	statuses, err := migrator.Status(ctx)
+	be.NilErr(t, err)
+	be.Equal(t, len(statuses), <-counts+<-counts)
+	for _, status := range statuses {
+		be.True(t, status.Applied)
+	}
+}
+
+// migrateDownTo reverts migrations until version is the latest applied
+func migrateDownTo(t *testing.T, migrator migrate.Migrator, version int64) {
+	t.Helper()
+	for {
+		reverted, err := migrator.Down(context.Background())
+		be.NilErr(t, err)
+		if reverted.Version == version+1 {
+			return
+		}
+	}
+}
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "realworld.db"))
+	be.NilErr(t, err)
+	t.Cleanup(func() { db.Close() })
+	migrator, err := sqlite.NewMigrator(db)
+	be.NilErr(t, err)
+	_, err = migrator.Up(context.Background())
+	be.NilErr(t, err)
+	return db
+}
+
+func newUser(name string) realworld.User {
+	return realworld.User{
+		ID:       name + "-id",
+		Profile:  realworld.Profile{Username: name},
+		Email:    name + "@email.com",
+		Password: "hash",
+	}
+}
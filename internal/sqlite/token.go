@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/raeperd/realworld"
+)
+
+// RefreshTokenRepository implements [realworld.RefreshTokenRepository]
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (tr *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token realworld.RefreshToken) error {
+	_, err := tr.db.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (hash, family, user_id, expires_at, token_version) VALUES (?, ?, ?, ?, ?)`,
+		token.Hash, token.Family, token.UserID, token.ExpiresAt.UnixNano(), token.TokenVersion,
+	)
+	return err
+}
+
+func (tr *RefreshTokenRepository) UseRefreshToken(ctx context.Context, hash string) (realworld.RefreshToken, error) {
+	// NOTE: single statement increments uses atomically, so that only the first use sees uses of 1
+	var token realworld.RefreshToken
+	var expiresAt int64
+	var uses int
+	err := tr.db.QueryRowContext(ctx,
+		`UPDATE refresh_tokens SET uses = uses + 1 WHERE hash = ? RETURNING hash, family, user_id, expires_at, token_version, uses`, hash,
+	).Scan(&token.Hash, &token.Family, &token.UserID, &expiresAt, &token.TokenVersion, &uses)
+	if errors.Is(err, sql.ErrNoRows) {
+		return realworld.RefreshToken{}, fmt.Errorf("%w not found", realworld.ErrInvalidRefreshToken)
+	}
+	if err != nil {
+		return realworld.RefreshToken{}, err
+	}
+	token.ExpiresAt = time.Unix(0, expiresAt)
+	token.Used = uses > 1
+	return token, nil
+}
+
+func (tr *RefreshTokenRepository) RevokeTokenFamily(ctx context.Context, family string) error {
+	_, err := tr.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE family = ?`, family)
+	return err
+}
+
+// RevokedTokenRepository implements [realworld.RevokedTokenRepository]
+type RevokedTokenRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewRevokedTokenRepository returns repository dropping tokens expired by now, e.g. [time.Now]
+func NewRevokedTokenRepository(db *sql.DB, now func() time.Time) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db, now: now}
+}
+
+func (rr *RevokedTokenRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	return inTx(ctx, rr.db, func(tx *sql.Tx) error {
+		// NOTE: rows of expired tokens are dropped on every revocation so that table stays bounded
+		if _, err := tx.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < ?`, rr.now().UnixNano()); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO revoked_tokens (jti, expires_at) VALUES (?, ?)`, jti, expiresAt.UnixNano())
+		return err
+	})
+}
+
+func (rr *RevokedTokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := rr.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = ?)`, jti).Scan(&revoked)
+	return revoked, err
+}
@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/raeperd/realworld"
+)
+
+// UserRepository implements [realworld.UserRepository]
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (ur *UserRepository) CreateUser(ctx context.Context, user realworld.User) (realworld.User, error) {
+	_, err := ur.db.ExecContext(ctx,
+		`INSERT INTO users (id, email, username, password, bio, image, token_version) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		user.ID, user.Email, user.Username, user.Password, user.Bio, user.Image, user.TokenVersion,
+	)
+	if isUniqueViolation(err) {
+		return realworld.User{}, userAlreadyExists(err)
+	}
+	if err != nil {
+		return realworld.User{}, err
+	}
+	return user, nil
+}
+
+func (ur *UserRepository) UpdateUser(ctx context.Context, user realworld.User) (realworld.User, error) {
+	err := ur.db.QueryRowContext(ctx,
+		`UPDATE users SET email = ?, username = ?, password = ?, bio = ?, image = ? WHERE id = ? RETURNING token_version`,
+		user.Email, user.Username, user.Password, user.Bio, user.Image, user.ID,
+	).Scan(&user.TokenVersion)
+	if isUniqueViolation(err) {
+		return realworld.User{}, userAlreadyExists(err)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return realworld.User{}, fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, user.ID)
+	}
+	if err != nil {
+		return realworld.User{}, err
+	}
+	return user, nil
+}
+
+func (ur *UserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	result, err := ur.db.ExecContext(ctx, `UPDATE users SET token_version = token_version + 1 WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return errors.Join(err, fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, id))
+	}
+	return nil
+}
+
+func (ur *UserRepository) FindUserByID(ctx context.Context, id string) (realworld.User, error) {
+	user, err := ur.findUser(ctx, "id = ?", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return realworld.User{}, fmt.Errorf("%w with id %s", realworld.ErrUserNotFound, id)
+	}
+	return user, err
+}
+
+func (ur *UserRepository) FindUsersByIDs(ctx context.Context, ids []string) ([]realworld.User, error) {
+	users := make([]realworld.User, 0, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+	in, args := inClause(ids)
+	rows, err := ur.db.QueryContext(ctx, `SELECT id, email, username, password, bio, image, token_version FROM users WHERE id IN `+in, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var user realworld.User
+		if err := rows.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.Bio, &user.Image, &user.TokenVersion); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (ur *UserRepository) FindUserByEmail(ctx context.Context, email string) (realworld.User, error) {
+	user, err := ur.findUser(ctx, "lower(email) = lower(?)", email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return realworld.User{}, fmt.Errorf("%w with email %s", realworld.ErrUserNotFound, email)
+	}
+	return user, err
+}
+
+func (ur *UserRepository) FindUserByUsername(ctx context.Context, username string) (realworld.User, error) {
+	user, err := ur.findUser(ctx, "lower(username) = lower(?)", username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return realworld.User{}, fmt.Errorf("%w with username %s", realworld.ErrUserNotFound, username)
+	}
+	return user, err
+}
+
+// findUser finds user by condition on one of unique columns, which must never be from user input.
+// email and username are compared in lower case to use their unique indexes.
+func (ur *UserRepository) findUser(ctx context.Context, condition, value string) (realworld.User, error) {
+	var user realworld.User
+	err := ur.db.QueryRowContext(ctx,
+		`SELECT id, email, username, password, bio, image, token_version FROM users WHERE `+condition, value,
+	).Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.Bio, &user.Image, &user.TokenVersion)
+	if err != nil {
+		return realworld.User{}, err
+	}
+	return user, nil
+}
+
+// userAlreadyExists returns error on the column of users violating unique constraint, reported like
+// "UNIQUE constraint failed: users.username" or "UNIQUE constraint failed: index 'users_username_lower'"
+func userAlreadyExists(err error) error {
+	if strings.Contains(err.Error(), "users.username") || strings.Contains(err.Error(), "users_username_lower") {
+		return realworld.ErrorUserAlreadyExists("username")
+	}
+	return realworld.ErrorUserAlreadyExists("email")
+}
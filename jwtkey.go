@@ -0,0 +1,274 @@
+package realworld
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// jwtKey signs and verifies JWT with the single algorithm it is for
+type jwtKey interface {
+	// alg is the JWS "alg" header value
+	alg() string
+	sign(input []byte) ([]byte, error)
+	verify(input, signature []byte) bool
+	// jwk returns public key without kid, alg and use, or false for symmetric key never to be published
+	jwk() (JWK, bool)
+}
+
+// JWK is JSON Web Key of RFC 7517 holding public key only
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is JSON Web Key Set published for verifiers
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// thumbprint is JWK thumbprint of RFC 7638 hashing required members in lexicographic order
+func (k JWK) thumbprint() string {
+	var members string
+	switch k.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, k.E, k.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, k.Crv, k.X, k.Y)
+	default:
+		members = fmt.Sprintf(`{"crv":"%s","kty":"%s","x":"%s"}`, k.Crv, k.Kty, k.X)
+	}
+	digest := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// errVerifyOnly is returned on signing with key loaded from public key
+var errVerifyOnly = errors.New("jwt: public key can only verify")
+
+// parseJWTKeyPEM picks algorithm from the key type of PEM encoded private or public key.
+// Private keys are PKCS #8, PKCS #1 or SEC 1 and public keys are PKIX or PKCS #1.
+func parseJWTKeyPEM(data []byte) (jwtKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: no PEM block found")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("jwt: unsupported PEM block type %s", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt: parse %s: %w", block.Type, err)
+	}
+	return newJWTKey(key)
+}
+
+func newJWTKey(key any) (jwtKey, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return newRSAKey(key, &key.PublicKey)
+	case *rsa.PublicKey:
+		return newRSAKey(nil, key)
+	case *ecdsa.PrivateKey:
+		return newECDSAKey(key, &key.PublicKey)
+	case *ecdsa.PublicKey:
+		return newECDSAKey(nil, key)
+	case ed25519.PrivateKey:
+		return ed25519Key{private: key, public: key.Public().(ed25519.PublicKey)}, nil
+	case ed25519.PublicKey:
+		return ed25519Key{public: key}, nil
+	default:
+		return nil, fmt.Errorf("jwt: unsupported key type %T", key)
+	}
+}
+
+type hmacKey struct {
+	secret []byte
+}
+
+func (k hmacKey) alg() string { return "HS256" }
+
+func (k hmacKey) sign(input []byte) ([]byte, error) {
+	h := hmac.New(sha256.New, k.secret)
+	if _, err := h.Write(input); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func (k hmacKey) verify(input, signature []byte) bool {
+	expected, err := k.sign(input)
+	return err == nil && hmac.Equal(signature, expected)
+}
+
+func (k hmacKey) jwk() (JWK, bool) { return JWK{}, false }
+
+// rsaKey signs with RS256. private is nil for verify only key.
+type rsaKey struct {
+	private *rsa.PrivateKey
+	public  *rsa.PublicKey
+}
+
+func newRSAKey(private *rsa.PrivateKey, public *rsa.PublicKey) (rsaKey, error) {
+	if public.N.BitLen() < 2048 {
+		return rsaKey{}, fmt.Errorf("jwt: RSA key of %d bits is shorter than 2048 bits", public.N.BitLen())
+	}
+	return rsaKey{private: private, public: public}, nil
+}
+
+func (k rsaKey) alg() string { return "RS256" }
+
+func (k rsaKey) sign(input []byte) ([]byte, error) {
+	if k.private == nil {
+		return nil, errVerifyOnly
+	}
+	digest := sha256.Sum256(input)
+	return rsa.SignPKCS1v15(rand.Reader, k.private, crypto.SHA256, digest[:])
+}
+
+func (k rsaKey) verify(input, signature []byte) bool {
+	digest := sha256.Sum256(input)
+	return rsa.VerifyPKCS1v15(k.public, crypto.SHA256, digest[:], signature) == nil
+}
+
+func (k rsaKey) jwk() (JWK, bool) {
+	return JWK{
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(k.public.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.public.E)).Bytes()),
+	}, true
+}
+
+// ecdsaKey signs with ES256, ES384 or ES512 by curve of the key. private is nil for verify only key.
+type ecdsaKey struct {
+	private *ecdsa.PrivateKey
+	public  *ecdsa.PublicKey
+	name    string
+	hash    crypto.Hash
+}
+
+func newECDSAKey(private *ecdsa.PrivateKey, public *ecdsa.PublicKey) (ecdsaKey, error) {
+	key := ecdsaKey{private: private, public: public}
+	switch public.Curve {
+	case elliptic.P256():
+		key.name, key.hash = "ES256", crypto.SHA256
+	case elliptic.P384():
+		key.name, key.hash = "ES384", crypto.SHA384
+	case elliptic.P521():
+		key.name, key.hash = "ES512", crypto.SHA512
+	default:
+		return ecdsaKey{}, fmt.Errorf("jwt: unsupported curve %s", public.Curve.Params().Name)
+	}
+	return key, nil
+}
+
+func (k ecdsaKey) alg() string { return k.name }
+
+// size is byte length of each of r and s in signature
+func (k ecdsaKey) size() int {
+	return (k.public.Curve.Params().BitSize + 7) / 8
+}
+
+func (k ecdsaKey) digest(input []byte) []byte {
+	switch k.hash {
+	case crypto.SHA384:
+		digest := sha512.Sum384(input)
+		return digest[:]
+	case crypto.SHA512:
+		digest := sha512.Sum512(input)
+		return digest[:]
+	default:
+		digest := sha256.Sum256(input)
+		return digest[:]
+	}
+}
+
+// sign encodes signature as r and s concatenated as JWS requires instead of ASN.1
+func (k ecdsaKey) sign(input []byte) ([]byte, error) {
+	if k.private == nil {
+		return nil, errVerifyOnly
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, k.private, k.digest(input))
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, 2*k.size())
+	r.FillBytes(signature[:k.size()])
+	s.FillBytes(signature[k.size():])
+	return signature, nil
+}
+
+func (k ecdsaKey) verify(input, signature []byte) bool {
+	if len(signature) != 2*k.size() {
+		return false
+	}
+	r := new(big.Int).SetBytes(signature[:k.size()])
+	s := new(big.Int).SetBytes(signature[k.size():])
+	return ecdsa.Verify(k.public, k.digest(input), r, s)
+}
+
+func (k ecdsaKey) jwk() (JWK, bool) {
+	x := make([]byte, k.size())
+	y := make([]byte, k.size())
+	k.public.X.FillBytes(x)
+	k.public.Y.FillBytes(y)
+	return JWK{
+		Kty: "EC",
+		Crv: k.public.Curve.Params().Name,
+		X:   base64.RawURLEncoding.EncodeToString(x),
+		Y:   base64.RawURLEncoding.EncodeToString(y),
+	}, true
+}
+
+// ed25519Key signs with EdDSA. private is nil for verify only key.
+type ed25519Key struct {
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+}
+
+func (k ed25519Key) alg() string { return "EdDSA" }
+
+func (k ed25519Key) sign(input []byte) ([]byte, error) {
+	if k.private == nil {
+		return nil, errVerifyOnly
+	}
+	return ed25519.Sign(k.private, input), nil
+}
+
+func (k ed25519Key) verify(input, signature []byte) bool {
+	return ed25519.Verify(k.public, input, signature)
+}
+
+func (k ed25519Key) jwk() (JWK, bool) {
+	return JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(k.public)}, true
+}
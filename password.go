@@ -0,0 +1,174 @@
+package realworld
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher hashes passwords into strings encoding algorithm and cost along with hash,
+// so that hashes stored with outdated parameters can be told apart and rehashed.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash in constant time
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash reports whether hash is produced with other algorithm or parameters than the hasher uses
+	NeedsRehash(hash string) bool
+}
+
+// Argon2idParams are parameters of argon2id. Memory is in KiB.
+type Argon2idParams struct {
+	Memory     uint32
+	Iterations uint32
+	Threads    uint8
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+// DefaultArgon2idParams follows the recommendation of RFC 9106 for memory constrained environments
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:     64 * 1024,
+	Iterations: 3,
+	Threads:    4,
+	SaltLength: 16,
+	KeyLength:  32,
+}
+
+// Argon2idHasher implements [PasswordHasher] encoding hashes in PHC string format
+// such as $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+func NewArgon2idHasher(params Argon2idParams) Argon2idHasher {
+	return Argon2idHasher{params: params}
+}
+
+func (a Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.params.Iterations, a.params.Memory, a.params.Threads, a.params.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		a.params.Memory, a.params.Iterations, a.params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a Argon2idHasher) Verify(hash, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Threads, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (a Argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, _, err := decodeArgon2id(hash)
+	return err != nil || params != a.params
+}
+
+func decodeArgon2id(hash string) (Argon2idParams, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2idParams{}, nil, nil, errors.New("argon2id: invalid hash format")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("argon2id: unsupported version %s", parts[2])
+	}
+	var params Argon2idParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Threads); err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("argon2id: invalid parameters %s: %w", parts[3], err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("argon2id: invalid salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("argon2id: invalid key: %w", err)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
+
+// BcryptHasher implements [PasswordHasher] with bcrypt, which encodes cost in its hash
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) BcryptHasher {
+	return BcryptHasher{cost: cost}
+}
+
+func (b BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", fmt.Errorf("%w: password must not exceed 72 bytes", ErrBadRequest)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (b BcryptHasher) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (b BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != b.cost
+}
+
+// MigratingHasher implements [PasswordHasher] hashing with hasher while verifying hashes of any known algorithm,
+// which is told by prefix of the hash. Users whose password is hashed with algorithm used before are then
+// able to log in, and their hash is replaced on login as [PasswordHasher.NeedsRehash] of hasher reports.
+type MigratingHasher struct {
+	hasher PasswordHasher
+}
+
+func NewMigratingHasher(hasher PasswordHasher) MigratingHasher {
+	return MigratingHasher{hasher: hasher}
+}
+
+func (m MigratingHasher) Hash(password string) (string, error) {
+	return m.hasher.Hash(password)
+}
+
+func (m MigratingHasher) Verify(hash, password string) (bool, error) {
+	return m.verifierOf(hash).Verify(hash, password)
+}
+
+func (m MigratingHasher) NeedsRehash(hash string) bool {
+	return m.hasher.NeedsRehash(hash)
+}
+
+// verifierOf returns hasher of algorithm hash is produced with, which takes its parameters from hash.
+// hasher is returned for hash of unknown algorithm so that it reports the error.
+func (m MigratingHasher) verifierOf(hash string) PasswordHasher {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return Argon2idHasher{}
+	case strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$"):
+		return BcryptHasher{}
+	default:
+		return m.hasher
+	}
+}
@@ -0,0 +1,74 @@
+package realworld_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlmjohnson/be"
+	"github.com/raeperd/realworld"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	params := realworld.Argon2idParams{Memory: 1024, Iterations: 1, Threads: 1, SaltLength: 16, KeyLength: 32}
+	stronger := params
+	stronger.Iterations = 2
+	testcases := map[string]struct {
+		hasher   realworld.PasswordHasher
+		stronger realworld.PasswordHasher
+	}{
+		"argon2id": {realworld.NewArgon2idHasher(params), realworld.NewArgon2idHasher(stronger)},
+		"bcrypt":   {realworld.NewBcryptHasher(bcrypt.MinCost), realworld.NewBcryptHasher(bcrypt.MinCost + 1)},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			hash, err := tc.hasher.Hash("password")
+			be.NilErr(t, err)
+			be.Unequal(t, "password", hash)
+
+			other, err := tc.hasher.Hash("password")
+			be.NilErr(t, err)
+			be.Unequal(t, hash, other)
+
+			matched, err := tc.hasher.Verify(hash, "password")
+			be.NilErr(t, err)
+			be.True(t, matched)
+
+			matched, err = tc.hasher.Verify(hash, "wrong-password")
+			be.NilErr(t, err)
+			be.False(t, matched)
+
+			be.False(t, tc.hasher.NeedsRehash(hash))
+			be.True(t, tc.stronger.NeedsRehash(hash))
+			matched, err = tc.stronger.Verify(hash, "password")
+			be.NilErr(t, err)
+			be.True(t, matched)
+		})
+	}
+}
+
+func TestMigratingHasher(t *testing.T) {
+	params := realworld.Argon2idParams{Memory: 1024, Iterations: 1, Threads: 1, SaltLength: 16, KeyLength: 32}
+	hasher := realworld.NewMigratingHasher(realworld.NewArgon2idHasher(params))
+	bcryptHash, err := realworld.NewBcryptHasher(bcrypt.MinCost).Hash("password")
+	be.NilErr(t, err)
+
+	matched, err := hasher.Verify(bcryptHash, "password")
+	be.NilErr(t, err)
+	be.True(t, matched)
+	matched, err = hasher.Verify(bcryptHash, "wrong-password")
+	be.NilErr(t, err)
+	be.False(t, matched)
+	be.True(t, hasher.NeedsRehash(bcryptHash))
+
+	hash, err := hasher.Hash("password")
+	be.NilErr(t, err)
+	be.True(t, strings.HasPrefix(hash, "$argon2id$"))
+	be.False(t, hasher.NeedsRehash(hash))
+	matched, err = hasher.Verify(hash, "password")
+	be.NilErr(t, err)
+	be.True(t, matched)
+
+	_, err = hasher.Verify("unknown", "password")
+	be.Nonzero(t, err)
+}
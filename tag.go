@@ -0,0 +1,43 @@
+package realworld
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// TagRepository lists tags in use by at least one article.
+// Tags are stored along with articles in [ArticleRepository].
+type TagRepository interface {
+	// ListTags returns tags in use ordered alphabetically
+	ListTags(ctx context.Context) ([]string, error)
+}
+
+type TagService struct {
+	repo TagRepository
+}
+
+func NewTagService(repo TagRepository) TagService {
+	return TagService{repo: repo}
+}
+
+func (t TagService) ListTags(ctx context.Context) ([]string, error) {
+	return t.repo.ListTags(ctx)
+}
+
+// normalizeTags trims and lowercases tags, dropping empty and duplicated ones while keeping order
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
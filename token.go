@@ -0,0 +1,66 @@
+package realworld
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// DefaultRefreshTokenTTL is lifetime of each refresh token, renewed on every rotation
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+// RefreshToken is stored server-side for opaque token given to client, which is never stored itself.
+type RefreshToken struct {
+	// Hash is SHA-256 of the opaque token
+	Hash string
+	// Family is shared by every refresh token rotated from the one issued on login
+	Family    string
+	UserID    string
+	ExpiresAt time.Time
+	// TokenVersion is [User.TokenVersion] when issued
+	TokenVersion int
+	// Used is set once rotated, so that the token showing up again is detected as reuse
+	Used bool
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token RefreshToken) error
+	// UseRefreshToken marks token with hash used and returns it as it was before marked.
+	// It is atomic so that only one of concurrent rotations sees the token unused.
+	UseRefreshToken(ctx context.Context, hash string) (RefreshToken, error)
+	// RevokeTokenFamily deletes every refresh token of family
+	RevokeTokenFamily(ctx context.Context, family string) error
+}
+
+// RevokedTokenRepository holds jti of access tokens revoked before they expire
+type RevokedTokenRepository interface {
+	// RevokeToken keeps jti revoked until expiresAt, after which the token is rejected as expired anyway
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// newOpaqueToken returns random token for client and its hash to store
+func newOpaqueToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashOpaqueToken(token), nil
+}
+
+func hashOpaqueToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+func newTokenFamily() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,42 +1,178 @@
 package realworld
 
-import "context"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
 
-// WARN: Need password hashing in production
 type User struct {
+	// ID is UUIDv7 generated by [UserService] on creation, which never changes unlike Email
+	ID string
 	Profile
-	Email    string
+	Email string
+	// Password is hash encoded by [PasswordHasher] once stored
 	Password string
+	// TokenVersion is bumped to revoke every token issued to the user so far
+	TokenVersion int
 }
 
 type Profile struct {
 	Username string
 	Bio      string
 	Image    string
+	// Following is computed for the viewer and never persisted
+	Following bool
+}
+
+// UserUpdate holds the fields of [User] to change. nil fields are left untouched.
+type UserUpdate struct {
+	Email    *string
+	Username *string
+	Password *string
+	Bio      *string
+	Image    *string
 }
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user User) (User, error)
+	// UpdateUser replaces the user stored with user.ID except TokenVersion, which is returned as stored
+	UpdateUser(ctx context.Context, user User) (User, error)
+	// IncrementTokenVersion bumps [User.TokenVersion] atomically, so that concurrent update never restores it
+	IncrementTokenVersion(ctx context.Context, id string) error
+	FindUserByID(ctx context.Context, id string) (User, error)
+	// FindUsersByIDs returns users of ids in any order, skipping ids not found
+	FindUsersByIDs(ctx context.Context, ids []string) ([]User, error)
 	FindUserByEmail(ctx context.Context, email string) (User, error)
 	FindUserByUsername(ctx context.Context, username string) (User, error)
 }
 
+// FollowRepository stores the follow graph between users keyed by ID.
+type FollowRepository interface {
+	Follow(ctx context.Context, follower, followee string) error
+	Unfollow(ctx context.Context, follower, followee string) error
+	IsFollowing(ctx context.Context, follower, followee string) (bool, error)
+	Followees(ctx context.Context, follower string) ([]string, error)
+	// FolloweesAmong returns those of ids follower follows
+	FolloweesAmong(ctx context.Context, follower string, ids []string) ([]string, error)
+}
+
 type UserService struct {
-	repo UserRepository
+	repo    UserRepository
+	follows FollowRepository
+	hasher  PasswordHasher
 }
 
-func NewUserService(repo UserRepository) UserService {
-	return UserService{repo: repo}
+func NewUserService(repo UserRepository, follows FollowRepository, hasher PasswordHasher) UserService {
+	return UserService{repo: repo, follows: follows, hasher: hasher}
 }
 
 func (u UserService) CreateUser(ctx context.Context, user User) (User, error) {
+	hash, err := u.hasher.Hash(user.Password)
+	if err != nil {
+		return User{}, err
+	}
+	user.Password = hash
+	if user.ID, err = newUserID(time.Now()); err != nil {
+		return User{}, err
+	}
 	return u.repo.CreateUser(ctx, user)
 }
 
-func (u UserService) FindProfileByUsername(ctx context.Context, username string) (Profile, error) {
+func (u UserService) UpdateUser(ctx context.Context, id string, update UserUpdate) (User, error) {
+	user, err := u.repo.FindUserByID(ctx, id)
+	if err != nil {
+		return User{}, err
+	}
+	if update.Email != nil {
+		user.Email = *update.Email
+	}
+	if update.Username != nil {
+		user.Username = *update.Username
+	}
+	if update.Password != nil {
+		if user.Password, err = u.hasher.Hash(*update.Password); err != nil {
+			return User{}, err
+		}
+	}
+	if update.Bio != nil {
+		user.Bio = *update.Bio
+	}
+	if update.Image != nil {
+		user.Image = *update.Image
+	}
+	return u.repo.UpdateUser(ctx, user)
+}
+
+// FindProfileByUsername returns profile as seen by viewer ID, which is empty for anonymous viewer.
+func (u UserService) FindProfileByUsername(ctx context.Context, viewer, username string) (Profile, error) {
+	user, err := u.repo.FindUserByUsername(ctx, username)
+	if err != nil {
+		return Profile{}, err
+	}
+	return profileOf(ctx, u.follows, viewer, user)
+}
+
+func (u UserService) FollowUser(ctx context.Context, follower, username string) (Profile, error) {
 	user, err := u.repo.FindUserByUsername(ctx, username)
 	if err != nil {
 		return Profile{}, err
 	}
+	if user.ID == follower {
+		return Profile{}, fmt.Errorf("%w: cannot follow yourself", ErrBadRequest)
+	}
+	if err := u.follows.Follow(ctx, follower, user.ID); err != nil {
+		return Profile{}, err
+	}
+	user.Following = true
 	return user.Profile, nil
 }
+
+func (u UserService) UnfollowUser(ctx context.Context, follower, username string) (Profile, error) {
+	user, err := u.repo.FindUserByUsername(ctx, username)
+	if err != nil {
+		return Profile{}, err
+	}
+	if err := u.follows.Unfollow(ctx, follower, user.ID); err != nil {
+		return Profile{}, err
+	}
+	user.Following = false
+	return user.Profile, nil
+}
+
+// profileOf returns profile of user as seen by viewer ID, which is empty for anonymous viewer.
+func profileOf(ctx context.Context, follows FollowRepository, viewer string, user User) (Profile, error) {
+	if viewer == "" || viewer == user.ID {
+		return user.Profile, nil
+	}
+	following, err := follows.IsFollowing(ctx, viewer, user.ID)
+	if err != nil {
+		return Profile{}, err
+	}
+	user.Following = following
+	return user.Profile, nil
+}
+
+func profileByID(ctx context.Context, users UserRepository, follows FollowRepository, viewer, id string) (Profile, error) {
+	user, err := users.FindUserByID(ctx, id)
+	if err != nil {
+		return Profile{}, err
+	}
+	return profileOf(ctx, follows, viewer, user)
+}
+
+// newUserID returns UUIDv7 of RFC 9562, which starts with unix milliseconds so that IDs sort by creation time
+func newUserID(now time.Time) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(now.UnixMilli()))
+	copy(b[:6], ms[2:])
+	b[6] = 0x70 | b[6]&0x0f
+	b[8] = 0x80 | b[8]&0x3f
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
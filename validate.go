@@ -0,0 +1,102 @@
+package realworld
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"unicode/utf8"
+)
+
+// Rule returns message describing how value violates the rule, or empty string if value satisfies it.
+// Messages follow the RealWorld spec, e.g. "is invalid", to be shown after the field name.
+type Rule func(value string) string
+
+// Rules of user fields. Login only requires fields not to be empty
+// so that users registered before a rule was tightened can still sign in.
+var (
+	EmailRules    = []Rule{Required, MaxLength(254), EmailAddress}
+	UsernameRules = []Rule{Required, Length(3, 32), UsernameCharset}
+	// PasswordRules caps password at 72 bytes, which is the most bcrypt hashes, so that any [PasswordHasher] accepts it
+	PasswordRules = []Rule{Required, MinLength(8), MaxBytes(72)}
+	// ImageRules allow empty image to unset it
+	ImageRules = []Rule{HTTPURL}
+)
+
+// ErrorIfInvalid returns [FieldError] wrapping [ErrBadRequest] on name with message of the first rule value violates
+func ErrorIfInvalid(name, value string, rules ...Rule) error {
+	for _, rule := range rules {
+		if message := rule(value); message != "" {
+			return FieldError{Err: ErrBadRequest, Field: name, Message: message}
+		}
+	}
+	return nil
+}
+
+func Required(value string) string {
+	if value == "" {
+		return "can't be blank"
+	}
+	return ""
+}
+
+// Length bounds number of characters of value, not bytes
+func Length(min, max int) Rule {
+	return func(value string) string {
+		switch n := utf8.RuneCountInString(value); {
+		case n < min:
+			return fmt.Sprintf("is too short (minimum is %d characters)", min)
+		case max < n:
+			return fmt.Sprintf("is too long (maximum is %d characters)", max)
+		}
+		return ""
+	}
+}
+
+func MinLength(min int) Rule {
+	return Length(min, math.MaxInt)
+}
+
+func MaxLength(max int) Rule {
+	return Length(0, max)
+}
+
+func MaxBytes(max int) Rule {
+	return func(value string) string {
+		if max < len(value) {
+			return fmt.Sprintf("is too long (maximum is %d bytes)", max)
+		}
+		return ""
+	}
+}
+
+// EmailAddress accepts bare address such as user@example.com, but not with display name or angle brackets
+func EmailAddress(value string) string {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return "is invalid"
+	}
+	return ""
+}
+
+// UsernameCharset accepts ASCII letters, digits, underscore and hyphen, which are safe in profile path
+func UsernameCharset(value string) string {
+	for _, r := range value {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_' || r == '-') {
+			return "is invalid"
+		}
+	}
+	return ""
+}
+
+// HTTPURL accepts absolute http or https URL with host, or empty string
+func HTTPURL(value string) string {
+	if value == "" {
+		return ""
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "is invalid"
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package realworld_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/carlmjohnson/be"
+	"github.com/raeperd/realworld"
+)
+
+func TestErrorIfInvalid(t *testing.T) {
+	testcases := []struct {
+		name    string
+		value   string
+		rules   []realworld.Rule
+		message string
+	}{
+		{"email", "user@email.com", realworld.EmailRules, ""},
+		{"email", "", realworld.EmailRules, "can't be blank"},
+		{"email", "user", realworld.EmailRules, "is invalid"},
+		{"email", "User <user@email.com>", realworld.EmailRules, "is invalid"},
+		{"email", strings.Repeat("a", 250) + "@e.com", realworld.EmailRules, "is too long (maximum is 254 characters)"},
+		{"username", "user_name-1", realworld.UsernameRules, ""},
+		{"username", "ab", realworld.UsernameRules, "is too short (minimum is 3 characters)"},
+		{"username", "user/name", realworld.UsernameRules, "is invalid"},
+		{"username", "사용자이름", realworld.UsernameRules, "is invalid"},
+		{"password", "password", realworld.PasswordRules, ""},
+		{"password", "short", realworld.PasswordRules, "is too short (minimum is 8 characters)"},
+		{"password", strings.Repeat("비", 25), realworld.PasswordRules, "is too long (maximum is 72 bytes)"},
+		{"image", "", realworld.ImageRules, ""},
+		{"image", "https://example.com/image.png", realworld.ImageRules, ""},
+		{"image", "/image.png", realworld.ImageRules, "is invalid"},
+		{"image", "javascript:alert(1)", realworld.ImageRules, "is invalid"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name+"="+tc.value, func(t *testing.T) {
+			err := realworld.ErrorIfInvalid(tc.name, tc.value, tc.rules...)
+			if tc.message == "" {
+				be.NilErr(t, err)
+				return
+			}
+			var fieldErr realworld.FieldError
+			be.True(t, errors.As(err, &fieldErr))
+			be.True(t, errors.Is(err, realworld.ErrBadRequest))
+			be.Equal(t, tc.name, fieldErr.Field)
+			be.Equal(t, tc.message, fieldErr.Message)
+		})
+	}
+}